@@ -7,11 +7,14 @@ import (
 
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Client"
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	si "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerInfo"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Stats"
 )
 
 const (
 	HorizontalLine = "------------------------------------------------------------"
 	ItemLenLimit   = 60
+	ListPageSize   = 100
 )
 
 func makeSomeActions(cli *client.Client, appMustBeStopped *chan bool) {
@@ -34,13 +37,19 @@ func makeSomeActions(cli *client.Client, appMustBeStopped *chan bool) {
 		}
 
 		switch action {
-		case 'r', 'R':
+		case 'r', 'R', 'c', 'C', 'i', 'I':
 		case 'g', 'G', 'e', 'E', 's', 'S', 'f', 'F':
 			uid, err = getUserInputString(HintUid)
 			if err != nil {
 				log.Println(err.Error())
 				continue
 			}
+		case 'l', 'L', 'w', 'W':
+			uid, err = getUserInputString(HintPrefix)
+			if err != nil {
+				log.Println(err.Error())
+				continue
+			}
 		default:
 			continue
 		}
@@ -52,10 +61,18 @@ func makeSomeActions(cli *client.Client, appMustBeStopped *chan bool) {
 			cerr = processEKeys(cli, uid)
 		case 's', 'S':
 			cerr = processSKeys(cli, uid)
+		case 'l', 'L':
+			cerr = processLKeys(cli, uid)
 		case 'f', 'F':
 			cerr = cli.ForgetRecord(uid)
 		case 'r', 'R':
 			cerr = cli.ResetCache()
+		case 'w', 'W':
+			cerr = processWKeys(cli, uid)
+		case 'c', 'C':
+			cerr = processCKeys(cli)
+		case 'i', 'I':
+			cerr = processIKeys(cli)
 		default:
 			continue
 		}
@@ -159,3 +176,87 @@ func processSKeys(cli *client.Client, uid string) (cerr *ce.CommonError) {
 
 	return nil
 }
+
+func processLKeys(cli *client.Client, prefix string) (cerr *ce.CommonError) {
+	var uids []string
+	var cursor string
+	var count int
+
+	fmt.Println("Records:")
+	fmt.Println(HorizontalLine)
+	for {
+		uids, cursor, cerr = cli.ListRecords(prefix, cursor, ListPageSize)
+		if cerr != nil {
+			return cerr
+		}
+
+		for _, uid := range uids {
+			fmt.Println(uid)
+		}
+		count += len(uids)
+
+		if len(cursor) == 0 {
+			break
+		}
+	}
+	fmt.Println(HorizontalLine)
+	fmt.Printf("Records Count: %d.\r\n", count)
+
+	return nil
+}
+
+func processWKeys(cli *client.Client, prefix string) (cerr *ce.CommonError) {
+	t1 := time.Now()
+
+	var loaded, failed int
+	loaded, failed, cerr = cli.WarmRecordsWithPrefix(prefix)
+	if cerr != nil {
+		return cerr
+	}
+
+	t1e := time.Now().Sub(t1)
+
+	fmt.Printf("Request Duration: %d µs. Records Loaded: %d. Records Failed: %d.\r\n",
+		t1e.Microseconds(), loaded, failed)
+
+	return nil
+}
+
+func processCKeys(cli *client.Client) (cerr *ce.CommonError) {
+	var cs *stats.CacheStats
+	cs, cerr = cli.GetCacheStats()
+	if cerr != nil {
+		return cerr
+	}
+
+	fmt.Println("Cache Statistics:")
+	fmt.Println(HorizontalLine)
+	fmt.Printf("Hits: %d. Misses: %d. Hit Ratio: %.2f %%.\r\n", cs.Hits, cs.Misses, cs.HitRatio()*100)
+	fmt.Printf("Entries: %d. Volume: %d of %d Bytes.\r\n", cs.Entries, cs.Volume, cs.VolumeMax)
+	fmt.Printf("Evictions: %d. Disk Reads: %d. Rejected Records: %d.\r\n", cs.Evictions, cs.DiskReads, cs.RejectedRecords)
+	fmt.Printf("Compressed Entries: %d. Volume: %d of %d Bytes.\r\n", cs.CompressedEntries, cs.CompressedVolume, cs.CompressedVolumeMax)
+	fmt.Println(HorizontalLine)
+
+	return nil
+}
+
+func processIKeys(cli *client.Client) (cerr *ce.CommonError) {
+	var info *si.ServerInfo
+	info, cerr = cli.GetServerInfo()
+	if cerr != nil {
+		return cerr
+	}
+
+	fmt.Println("Server Information:")
+	fmt.Println(HorizontalLine)
+	fmt.Printf("Program: %s %s. Go: %s.\r\n", info.Build.ProgramName, info.Build.ProgramVersion, info.Build.GoVersion)
+	fmt.Printf("Started: %s. Uptime: %v.\r\n", info.StartTime.Format(time.RFC3339), info.Uptime.Round(time.Second))
+	fmt.Printf("Connections: %d Main, %d Auxiliary.\r\n", info.MainConnections, info.AuxConnections)
+	fmt.Println("Settings:")
+	for _, p := range info.Settings {
+		fmt.Printf("  %s = %s\r\n", p.Name, p.Value)
+	}
+	fmt.Println(HorizontalLine)
+
+	return nil
+}
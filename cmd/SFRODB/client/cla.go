@@ -4,15 +4,23 @@ import (
 	"errors"
 	"os"
 
-	"github.com/vault-thirteen/auxie/number"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/socket"
 )
 
 const ErrSyntax = "syntax error"
 
+// EnvAuxSecret is the name of an environment variable with the secret for
+// authentication on the auxiliary port. The secret is not passed as an
+// argument, so that it is not visible in the list of processes.
+const EnvAuxSecret = "SFRODB_AUX_SECRET"
+
 type CommandLineArguments struct {
-	Host     string
-	MainPort uint16
-	AuxPort  uint16
+	Host       string
+	MainPort   uint16
+	AuxPort    uint16
+	MainSocket string
+	AuxSocket  string
+	AuxSecret  string
 }
 
 func readCLA() (cla *CommandLineArguments, err error) {
@@ -21,15 +29,16 @@ func readCLA() (cla *CommandLineArguments, err error) {
 	}
 
 	cla = &CommandLineArguments{
-		Host: os.Args[1],
+		Host:      os.Args[1],
+		AuxSecret: os.Getenv(EnvAuxSecret),
 	}
 
-	cla.MainPort, err = number.ParseUint16(os.Args[2])
+	cla.MainPort, cla.MainSocket, err = socket.ParsePort(os.Args[2])
 	if err != nil {
 		return nil, err
 	}
 
-	cla.AuxPort, err = number.ParseUint16(os.Args[3])
+	cla.AuxPort, cla.AuxSocket, err = socket.ParsePort(os.Args[3])
 	if err != nil {
 		return nil, err
 	}
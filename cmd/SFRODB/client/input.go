@@ -12,10 +12,15 @@ const (
 		"[G] = Get/Show a Record;\r\n" +
 		"[E] = Check Record's Existence;\r\n" +
 		"[S] = Check File's Existence;\r\n" +
+		"[L] = List Records;\r\n" +
 		"[F] = Forget/Remove a Record from Cache;\r\n" +
 		"[R] = Reset/Clear the Cache;\r\n" +
+		"[W] = Warm the Cache with Records;\r\n" +
+		"[C] = Show Cache Statistics;\r\n" +
+		"[I] = Show Server Information;\r\n" +
 		"[Q] = Quit/Exit.\r\n> "
 	HintUid      = "Enter the UID > "
+	HintPrefix   = "Enter the UID prefix (may be empty) > "
 	HintDataSize = "Data is quite large. Do you want to see it ? [Y] = Yes; [N] = No. > "
 )
 
@@ -12,6 +12,7 @@ import (
 	ver "github.com/vault-thirteen/auxie/Versioneer/classes/Versioneer"
 	"github.com/vault-thirteen/auxie/random"
 
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Auth"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Client"
 	cs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ClientSettings"
 )
@@ -23,8 +24,11 @@ func main() {
 	mustBeNoError(err)
 
 	var stn *cs.ClientSettings
-	stn, err = cs.New(cla.Host, cla.MainPort, cla.AuxPort, 0)
+	stn, err = cs.New(cla.Host, cla.MainPort, cla.AuxPort)
 	mustBeNoError(err)
+	stn.MainSocket = cla.MainSocket
+	stn.AuxSocket = cla.AuxSocket
+	stn.AuxSecret = auth.Secret(cla.AuxSecret)
 	log.Println("Settings:", stn)
 
 	var clientId uint
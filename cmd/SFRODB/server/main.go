@@ -6,16 +6,16 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	ver "github.com/vault-thirteen/auxie/Versioneer/classes/Versioneer"
 
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Server"
+	si "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerInfo"
 	ss "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerSettings"
 )
 
 func main() {
-	showIntro()
+	versioneer := showIntro()
 
 	cla, err := readCLA()
 	mustBeNoError(err)
@@ -30,6 +30,11 @@ func main() {
 	var srv *server.Server
 	srv, err = server.New(stn)
 	mustBeNoError(err)
+	srv.SetBuildInfo(si.BuildInfo{
+		ProgramName:    versioneer.ProgramName(),
+		ProgramVersion: versioneer.ProgramVersionString(),
+		GoVersion:      versioneer.GoVersion(),
+	})
 
 	cerr := srv.Start()
 	if cerr != nil {
@@ -46,7 +51,6 @@ func main() {
 	if cerr != nil {
 		log.Println(cerr)
 	}
-	time.Sleep(time.Second)
 }
 
 func mustBeNoError(err error) {
@@ -55,12 +59,14 @@ func mustBeNoError(err error) {
 	}
 }
 
-func showIntro() {
+func showIntro() (versioneer *ver.Versioneer) {
 	versioneer, err := ver.New(false)
 	mustBeNoError(err)
 	versioneer.ShowIntroText("Server")
 	versioneer.ShowComponentsInfoText()
 	fmt.Println()
+
+	return versioneer
 }
 
 func waitForQuitSignalFromOS(quitChan *chan bool) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"sync"
 	"sync/atomic"
@@ -14,6 +15,7 @@ import (
 	cs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ClientSettings"
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
 	poc "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/PoolOfClients"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/socket"
 )
 
 const (
@@ -56,9 +58,9 @@ func NewServer(stn *ss.Settings) (srv *Server, err error) {
 
 	srv = &Server{
 		settings:      stn,
-		listenDsn:     fmt.Sprintf("%s:%d", stn.ServerHost, stn.ServerPort),
-		dbDsnA:        fmt.Sprintf("%s:%d", stn.DbHost, stn.DbPortA),
-		dbDsnB:        fmt.Sprintf("%s:%d", stn.DbHost, stn.DbPortB),
+		listenDsn:     socket.Dsn(stn.ServerHost, stn.ServerPort, stn.ServerSocket),
+		dbDsnA:        socket.Dsn(stn.DbHost, stn.DbPortA, stn.DbSocketA),
+		dbDsnB:        socket.Dsn(stn.DbHost, stn.DbPortB, stn.DbSocketB),
 		dbClientLock:  new(sync.Mutex),
 		mustBeStopped: make(chan bool, 2),
 		subRoutines:   new(sync.WaitGroup),
@@ -82,11 +84,14 @@ func NewServer(stn *ss.Settings) (srv *Server, err error) {
 		srv.settings.DbHost,
 		srv.settings.DbPortA,
 		srv.settings.DbPortB,
-		cs.ResponseMessageLengthLimitDefault,
 	)
 	if err != nil {
 		return nil, err
 	}
+	dbClientSettings.MainSocket = srv.settings.DbSocketA
+	dbClientSettings.AuxSocket = srv.settings.DbSocketB
+	dbClientSettings.IsCompressionEnabled = srv.settings.IsGzipPassThroughEnabled
+	dbClientSettings.Tls = srv.settings.DbTls
 
 	srv.poolOfClients, err = poc.New(srv.settings.DbClientPoolSize, dbClientSettings)
 	if err != nil {
@@ -155,11 +160,15 @@ func (srv *Server) Stop() (cerr *ce.CommonError) {
 func (srv *Server) startHttpServer() {
 	go func() {
 		var listenError error
-		switch srv.settings.ServerModeId {
-		case ss.ServerModeIdHttp:
-			listenError = srv.httpServer.ListenAndServe()
-		case ss.ServerModeIdHttps:
-			listenError = srv.httpServer.ListenAndServeTLS(srv.settings.CertFile, srv.settings.KeyFile)
+		if len(srv.settings.ServerSocket) > 0 {
+			listenError = srv.serveUnixSocket()
+		} else {
+			switch srv.settings.ServerModeId {
+			case ss.ServerModeIdHttp:
+				listenError = srv.httpServer.ListenAndServe()
+			case ss.ServerModeIdHttps:
+				listenError = srv.httpServer.ListenAndServeTLS(srv.settings.CertFile, srv.settings.KeyFile)
+			}
 		}
 		if (listenError != nil) && (listenError != http.ErrServerClosed) {
 			srv.httpErrors <- listenError
@@ -167,6 +176,28 @@ func (srv *Server) startHttpServer() {
 	}()
 }
 
+// serveUnixSocket serves HTTP requests coming to the Unix socket.
+func (srv *Server) serveUnixSocket() (err error) {
+	var addr net.Addr
+	addr, err = socket.ResolveAddr("", 0, srv.settings.ServerSocket)
+	if err != nil {
+		return err
+	}
+
+	var listener net.Listener
+	listener, err = socket.Listen(addr)
+	if err != nil {
+		return err
+	}
+
+	switch srv.settings.ServerModeId {
+	case ss.ServerModeIdHttps:
+		return srv.httpServer.ServeTLS(listener, srv.settings.CertFile, srv.settings.KeyFile)
+	default:
+		return srv.httpServer.Serve(listener)
+	}
+}
+
 func (srv *Server) listenForHttpErrors() {
 	defer srv.subRoutines.Done()
 
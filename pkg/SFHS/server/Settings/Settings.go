@@ -2,10 +2,14 @@ package Settings
 
 import (
 	"errors"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	cs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ClientSettings"
+	ts "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/TlsSettings"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/socket"
 	ae "github.com/vault-thirteen/auxie/errors"
 	"github.com/vault-thirteen/auxie/number"
 	"github.com/vault-thirteen/auxie/reader"
@@ -45,6 +49,10 @@ type Settings struct {
 	// Server's Listen Port.
 	ServerPort uint16
 
+	// Path to a Unix socket which is used instead of the listen port when it
+	// is set.
+	ServerSocket string
+
 	// ServerMode is an HTTP mode selector.
 	// Possible values are: HTTP and HTTPS.
 	ServerModeStr string
@@ -61,6 +69,11 @@ type Settings struct {
 	DbPortA uint16
 	DbPortB uint16
 
+	// Paths to Unix sockets of the database which are used instead of the
+	// ports when they are set.
+	DbSocketA string
+	DbSocketB string
+
 	// DbClientPoolSize is the size of a pool of DB clients.
 	DbClientPoolSize int
 
@@ -76,6 +89,16 @@ type Settings struct {
 
 	// Allowed Origin for cross-origin requests (CORS).
 	AllowedOriginForCORS string
+
+	// IsGzipPassThroughEnabled enables compression of data sent by the
+	// database. Data compressed with gzip is sent to HTTP clients which accept
+	// it as is, with the 'Content-Encoding' header. This setting is optional,
+	// it is disabled by default.
+	IsGzipPassThroughEnabled bool
+
+	// DbTls configures TLS of connections to the database. This setting is
+	// optional, TLS is disabled by default.
+	DbTls *ts.TlsSettings
 }
 
 func NewSettingsFromFile(filePath string) (stn *Settings, err error) {
@@ -108,7 +131,7 @@ func NewSettingsFromFile(filePath string) (stn *Settings, err error) {
 	// Server Hostname & Port.
 	stn.ServerHost = strings.TrimSpace(string(buf[0]))
 
-	stn.ServerPort, err = number.ParseUint16(strings.TrimSpace(string(buf[1])))
+	stn.ServerPort, stn.ServerSocket, err = socket.ParsePort(string(buf[1]))
 	if err != nil {
 		return stn, err
 	}
@@ -129,12 +152,12 @@ func NewSettingsFromFile(filePath string) (stn *Settings, err error) {
 	// Database.
 	stn.DbHost = strings.TrimSpace(string(buf[5]))
 
-	stn.DbPortA, err = number.ParseUint16(strings.TrimSpace(string(buf[6])))
+	stn.DbPortA, stn.DbSocketA, err = socket.ParsePort(string(buf[6]))
 	if err != nil {
 		return stn, err
 	}
 
-	stn.DbPortB, err = number.ParseUint16(strings.TrimSpace(string(buf[7])))
+	stn.DbPortB, stn.DbSocketB, err = socket.ParsePort(string(buf[7]))
 	if err != nil {
 		return stn, err
 	}
@@ -159,19 +182,58 @@ func NewSettingsFromFile(filePath string) (stn *Settings, err error) {
 
 	stn.AllowedOriginForCORS = strings.TrimSpace(string(buf[12]))
 
+	// Optional settings.
+	var line string
+	line, err = readOptionalLine(rdr)
+	if err != nil {
+		return stn, err
+	}
+
+	if len(line) > 0 {
+		stn.IsGzipPassThroughEnabled, err = strconv.ParseBool(line)
+		if err != nil {
+			return stn, err
+		}
+	}
+
+	line, err = readOptionalLine(rdr)
+	if err != nil {
+		return stn, err
+	}
+
+	if len(line) > 0 {
+		stn.DbTls, err = ts.ParseClientTlsSettings(line)
+		if err != nil {
+			return stn, err
+		}
+	}
+
 	return stn, nil
 }
 
+// readOptionalLine reads a line which may be absent at the end of the file.
+// An absent line is returned as an empty string.
+func readOptionalLine(rdr *reader.Reader) (line string, err error) {
+	var ba []byte
+	ba, err = rdr.ReadLineEndingWithCRLF()
+	if (err != nil) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(ba)), nil
+}
+
 func (stn *Settings) Check() (err error) {
 	if len(stn.File) == 0 {
 		return errors.New(ErrFileIsNotSet)
 	}
 
-	if len(stn.ServerHost) == 0 {
+	// Host name is not needed for a Unix socket.
+	if (len(stn.ServerHost) == 0) && (len(stn.ServerSocket) == 0) {
 		return errors.New(ErrServerHostIsNotSet)
 	}
 
-	if stn.ServerPort == 0 {
+	if (stn.ServerPort == 0) && (len(stn.ServerSocket) == 0) {
 		return errors.New(ErrServerPortIsNotSet)
 	}
 
@@ -207,15 +269,18 @@ func (stn *Settings) Check() (err error) {
 		return errors.New(ErrServerMode)
 	}
 
-	if len(stn.DbHost) == 0 {
-		return errors.New(cs.ErrClientHostIsNotSet)
+	// Host name is not needed for Unix sockets.
+	if (len(stn.DbSocketA) == 0) || (len(stn.DbSocketB) == 0) {
+		if len(stn.DbHost) == 0 {
+			return errors.New(cs.ErrClientHostIsNotSet)
+		}
 	}
 
-	if stn.DbPortA == 0 {
+	if (stn.DbPortA == 0) && (len(stn.DbSocketA) == 0) {
 		return errors.New(cs.ErrClientPortIsNotSet)
 	}
 
-	if stn.DbPortB == 0 {
+	if (stn.DbPortB == 0) && (len(stn.DbSocketB) == 0) {
 		return errors.New(cs.ErrClientPortIsNotSet)
 	}
 
@@ -237,5 +302,12 @@ func (stn *Settings) Check() (err error) {
 
 	// AllowedOriginForCORS is not checked as it may be empty.
 
+	if stn.DbTls != nil {
+		err = stn.DbTls.CheckForClient()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -1,10 +1,12 @@
 package server
 
 import (
+	"context"
 	"time"
 
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Client"
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Compression"
 	ae "github.com/vault-thirteen/auxie/errors"
 )
 
@@ -39,7 +41,10 @@ func (srv *Server) returnClient(cli *client.Client, clientError *ce.CommonError)
 	return nil
 }
 
-func (srv *Server) getData(uid string) (data []byte, cerr *ce.CommonError) {
+// getData gets data from the database. When gzip is accepted by the HTTP
+// client and the pass-through is enabled, data may be returned compressed.
+// The request to the database is cancelled together with the context.
+func (srv *Server) getData(ctx context.Context, uid string, isGzipAccepted bool) (data []byte, c compression.Compression, cerr *ce.CommonError) {
 	srv.dbClientLock.Lock()
 	defer srv.dbClientLock.Unlock()
 
@@ -48,7 +53,7 @@ func (srv *Server) getData(uid string) (data []byte, cerr *ce.CommonError) {
 	cli, err = srv.takeClient()
 	if err != nil {
 		cerr = ce.NewClientError(err.Error(), 0, 0, client.ClientIdNone)
-		return nil, cerr
+		return nil, compression.Compression_None, cerr
 	}
 
 	defer func() {
@@ -60,10 +65,29 @@ func (srv *Server) getData(uid string) (data []byte, cerr *ce.CommonError) {
 		return
 	}()
 
-	data, cerr = cli.ShowData(uid)
+	if !srv.settings.IsGzipPassThroughEnabled || !isGzipAccepted {
+		data, cerr = cli.ShowDataCtx(ctx, uid)
+		if cerr != nil {
+			return nil, compression.Compression_None, cerr
+		}
+
+		return data, compression.Compression_None, nil
+	}
+
+	data, c, cerr = cli.ShowDataCompressedCtx(ctx, uid)
 	if cerr != nil {
-		return nil, cerr
+		return nil, compression.Compression_None, cerr
+	}
+
+	if (c == compression.Compression_None) || (c == compression.Compression_Gzip) {
+		return data, c, nil
+	}
+
+	// Other methods of compression are not passed through.
+	data, err = c.Decompress(data)
+	if err != nil {
+		return nil, compression.Compression_None, ce.NewClientError(err.Error(), 0, 0, cli.GetId())
 	}
 
-	return data, nil
+	return data, compression.Compression_None, nil
 }
@@ -4,32 +4,43 @@ import (
 	"log"
 	"net/http"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	ss "github.com/vault-thirteen/SFRODB/pkg/SFHS/server/Settings"
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Compression"
 	hdr "github.com/vault-thirteen/auxie/header"
 )
 
 func (srv *Server) httpRouter(rw http.ResponseWriter, req *http.Request) {
 	uid := req.URL.Path[1:]
 
-	data, cerr := srv.getData(uid)
+	data, c, cerr := srv.getData(req.Context(), uid, isGzipAccepted(req))
 	if cerr != nil {
 		srv.processError(rw, cerr)
 		return
 	}
 
-	srv.respondWithData(rw, data)
+	srv.respondWithData(rw, data, c)
 }
 
 func (srv *Server) respondWithData(
 	rw http.ResponseWriter,
 	//uid string,
 	data []byte,
+	c compression.Compression,
 ) {
 	rw.Header().Set(hdr.HttpHeaderContentType, srv.settings.MimeType)
 	rw.Header().Set(hdr.HttpHeaderServer, ServerName)
 
+	if srv.settings.IsGzipPassThroughEnabled {
+		rw.Header().Set(hdr.HttpHeaderVary, hdr.HttpHeaderAcceptEncoding)
+	}
+	if c != compression.Compression_None {
+		rw.Header().Set(hdr.HttpHeaderContentEncoding, c.String())
+	}
+
 	// CORS support.
 	if len(srv.settings.AllowedOriginForCORS) > 0 {
 		rw.Header().Set(hdr.HttpHeaderAccessControlAllowOrigin, srv.settings.AllowedOriginForCORS)
@@ -55,6 +66,32 @@ func (srv *Server) respondWithData(
 	}
 }
 
+// isGzipAccepted checks whether the HTTP client accepts data compressed with
+// gzip.
+func isGzipAccepted(req *http.Request) bool {
+	for _, value := range req.Header.Values(hdr.HttpHeaderAcceptEncoding) {
+		for _, coding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			if !strings.EqualFold(strings.TrimSpace(name), compression.Name_Gzip) {
+				continue
+			}
+
+			// Coding with a zero quality value is not acceptable.
+			q, hasQ := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if hasQ {
+				qv, err := strconv.ParseFloat(strings.TrimSpace(q), 64)
+				if (err != nil) || (qv == 0) {
+					return false
+				}
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
 func (srv *Server) processError(rw http.ResponseWriter, cerr *ce.CommonError) {
 	if cerr.IsClientError() {
 		rw.WriteHeader(http.StatusBadRequest)
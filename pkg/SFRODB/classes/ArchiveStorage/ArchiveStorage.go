@@ -0,0 +1,266 @@
+package as
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+	ae "github.com/vault-thirteen/auxie/errors"
+)
+
+// ArchiveStorage is a storage of data files in a ZIP or a TAR archive, see
+// the 'storage.Storage' interface. Files are read from the archive directly,
+// without unpacking it. The archive may be switched to another one at any
+// time; the switch is atomic, each request sees either the old archive or
+// the new one. The file of the old archive is closed when the files opened
+// from it are closed.
+type ArchiveStorage struct {
+	current     *archive
+	currentLock *sync.RWMutex
+
+	// Compressed files which are larger are not unpacked into memory.
+	inMemorySizeMax int64
+
+	// Switches are made one at a time.
+	switchLock *sync.Mutex
+}
+
+// New opens the archive. Compressed files of a ZIP archive which are not
+// larger than the limit are unpacked into memory when they are opened, e.g.
+// the limit of a cached item may be used. Larger files are unpacked while
+// being read.
+func New(archivePath string, inMemorySizeMax int64) (as *ArchiveStorage, err error) {
+	var arc *archive
+	arc, err = openArchive(archivePath, inMemorySizeMax)
+	if err != nil {
+		return nil, err
+	}
+
+	as = &ArchiveStorage{
+		current:         arc,
+		currentLock:     new(sync.RWMutex),
+		inMemorySizeMax: inMemorySizeMax,
+		switchLock:      new(sync.Mutex),
+	}
+
+	return as, nil
+}
+
+// GetArchivePath returns the path to the current archive.
+func (as *ArchiveStorage) GetArchivePath() (archivePath string) {
+	return as.getCurrent().filePath
+}
+
+// Switch opens another archive and puts it in place of the current one. The
+// current archive is kept when the new one can not be opened.
+func (as *ArchiveStorage) Switch(archivePath string) (err error) {
+	as.switchLock.Lock()
+	defer as.switchLock.Unlock()
+
+	return as.switchTo(archivePath)
+}
+
+// Reload opens the archive again when its file has been changed, e.g. when a
+// new version of the archive has been moved in place of the old one. The file
+// is compared by its size and modification time. Only when they differ, the
+// file is read to compare the checksum of its contents, so that a file which
+// has been touched without changes is not opened again. Files of the archive
+// are compared by their states, so that the changed ones could be found, see
+// the 'ScanFiles' method.
+func (as *ArchiveStorage) Reload() (isReloaded bool, err error) {
+	as.switchLock.Lock()
+	defer as.switchLock.Unlock()
+
+	arc := as.getCurrent()
+
+	var fi os.FileInfo
+	fi, err = os.Stat(arc.filePath)
+	if err != nil {
+		return false, err
+	}
+
+	if (fi.Size() == arc.state.Size) && fi.ModTime().Equal(arc.state.ModTime) {
+		return false, nil
+	}
+
+	var state storage.FileState
+	state, err = stateOfFile(arc.filePath)
+	if err != nil {
+		return false, err
+	}
+
+	if state.Checksum == arc.state.Checksum {
+		// The state is changed under the switch lock only, so that the file
+		// is not read again by the next check.
+		arc.state = state
+		return false, nil
+	}
+
+	err = as.switchTo(arc.filePath)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// stateOfFile returns the state of an archive file with the checksum of its
+// contents.
+func stateOfFile(filePath string) (state storage.FileState, err error) {
+	var file *os.File
+	file, err = os.Open(filePath)
+	if err != nil {
+		return state, err
+	}
+
+	var fi os.FileInfo
+	fi, err = file.Stat()
+	if err != nil {
+		return state, ae.Combine(err, file.Close())
+	}
+	state = storage.FileState{Size: fi.Size(), ModTime: fi.ModTime()}
+
+	state.Checksum, err = checksumOf(file)
+	if err != nil {
+		return state, ae.Combine(err, file.Close())
+	}
+
+	return state, file.Close()
+}
+
+// switchTo puts a new archive in place of the current one. The caller must
+// hold the switch lock.
+func (as *ArchiveStorage) switchTo(archivePath string) (err error) {
+	var arc *archive
+	arc, err = openArchive(archivePath, as.inMemorySizeMax)
+	if err != nil {
+		return err
+	}
+
+	as.currentLock.Lock()
+	old := as.current
+	as.current = arc
+	as.currentLock.Unlock()
+
+	return old.retire()
+}
+
+// Close closes the storage. Files which are still opened may be read until
+// they are closed.
+func (as *ArchiveStorage) Close() (err error) {
+	return as.getCurrent().retire()
+}
+
+// getCurrent returns the current archive.
+func (as *ArchiveStorage) getCurrent() (arc *archive) {
+	as.currentLock.RLock()
+	defer as.currentLock.RUnlock()
+
+	return as.current
+}
+
+// acquireCurrent returns the current archive registered as used, so that it
+// is not closed by a switch. The caller must release it.
+func (as *ArchiveStorage) acquireCurrent() (arc *archive, err error) {
+	as.currentLock.RLock()
+	defer as.currentLock.RUnlock()
+
+	if !as.current.acquire() {
+		return nil, os.ErrClosed
+	}
+
+	return as.current, nil
+}
+
+// findEntry finds a file in the archive.
+func findEntry(arc *archive, relPath string) (e *entry, err error) {
+	if !storage.IsRelPathValid(relPath) {
+		return nil, errors.New(storage.ErrRelPathIsNotValid)
+	}
+
+	var fileExists bool
+	e, fileExists = arc.entries[relPath]
+	if !fileExists {
+		return nil, fmt.Errorf(storage.ErrFileDoesNotExist, relPath)
+	}
+
+	return e, nil
+}
+
+// OpenFile opens a file for reading and returns its size. The caller must
+// close the file.
+func (as *ArchiveStorage) OpenFile(relPath string) (fileExists bool, f storage.File, fileSize int64, err error) {
+	var arc *archive
+	arc, err = as.acquireCurrent()
+	if err != nil {
+		return false, nil, 0, err
+	}
+
+	var e *entry
+	e, err = findEntry(arc, relPath)
+	if e == nil {
+		return false, nil, 0, ae.Combine(err, arc.release())
+	}
+
+	f, err = arc.openEntry(e)
+	if err != nil {
+		return true, nil, 0, err
+	}
+
+	return true, f, e.state.Size, nil
+}
+
+func (as *ArchiveStorage) FileExists(relPath string) (fileExists bool, err error) {
+	var e *entry
+	e, err = findEntry(as.getCurrent(), relPath)
+	if (e == nil) && (err != nil) && (err.Error() == storage.ErrRelPathIsNotValid) {
+		return false, err
+	}
+
+	return e != nil, nil
+}
+
+// StatFile returns the state of a file without reading it.
+func (as *ArchiveStorage) StatFile(relPath string) (fileExists bool, state storage.FileState, err error) {
+	var e *entry
+	e, err = findEntry(as.getCurrent(), relPath)
+	if e == nil {
+		return false, state, err
+	}
+
+	return true, e.state, nil
+}
+
+// ListFiles returns relative paths of all the files having the extension.
+// Paths are sorted.
+func (as *ArchiveStorage) ListFiles(extension string) (relPaths []string, err error) {
+	arc := as.getCurrent()
+
+	relPaths = make([]string, 0, len(arc.relPaths))
+	for _, relPath := range arc.relPaths {
+		if strings.HasSuffix(relPath, extension) {
+			relPaths = append(relPaths, relPath)
+		}
+	}
+
+	return relPaths, nil
+}
+
+// ScanFiles returns states of all the files having the extension by their
+// relative paths. States of files include their checksums. The archive is not
+// reloaded by a scan, see the 'Reload' method.
+func (as *ArchiveStorage) ScanFiles(extension string) (states map[string]storage.FileState, err error) {
+	arc := as.getCurrent()
+
+	states = make(map[string]storage.FileState)
+	for relPath, e := range arc.entries {
+		if strings.HasSuffix(relPath, extension) {
+			states[relPath] = e.state
+		}
+	}
+
+	return states, nil
+}
@@ -0,0 +1,306 @@
+package as_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	as "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ArchiveStorage"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+)
+
+// entryTime is the modification time of all the files of test archives, as
+// in archives made by reproducible builds.
+var entryTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// file is a file of a test archive.
+type file struct {
+	name string
+	data string
+}
+
+// writeZip writes a ZIP archive with the files in their order.
+func writeZip(tb testing.TB, filePath string, method uint16, files ...file) {
+	tb.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: method, Modified: entryTime})
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		_, err = w.Write([]byte(f.data))
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	writeFile(tb, filePath, buf.Bytes())
+}
+
+// writeTar writes a TAR archive with the files in their order.
+func writeTar(tb testing.TB, filePath string, files ...file) {
+	tb.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		err := tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Size: int64(len(f.data)), Mode: 0o644, ModTime: entryTime})
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		_, err = tw.Write([]byte(f.data))
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	err := tw.Close()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	writeFile(tb, filePath, buf.Bytes())
+}
+
+// writeFile writes the file keeping its modification time, so that only its
+// contents tell one version of the file from another.
+func writeFile(tb testing.TB, filePath string, data []byte) {
+	tb.Helper()
+
+	err := os.WriteFile(filePath, data, 0o644)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	err = os.Chtimes(filePath, entryTime, entryTime)
+	if err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// newStorage opens the archive, the storage is closed by the cleanup of the
+// test.
+func newStorage(tb testing.TB, filePath string, inMemorySizeMax int64) (s *as.ArchiveStorage) {
+	tb.Helper()
+
+	s, err := as.New(filePath, inMemorySizeMax)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() {
+		err := s.Close()
+		if err != nil {
+			tb.Error(err)
+		}
+	})
+
+	return s
+}
+
+// readFile reads a whole file of the storage.
+func readFile(tb testing.TB, s storage.Storage, relPath string) (data string) {
+	tb.Helper()
+
+	fileExists, f, _, err := s.OpenFile(relPath)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if !fileExists {
+		tb.Fatalf("file does not exist: %s", relPath)
+	}
+
+	buf, err := io.ReadAll(f)
+	err = errors.Join(err, f.Close())
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return string(buf)
+}
+
+func Test_DuplicateEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	zipPath := filepath.Join(dir, "a.zip")
+	writeZip(t, zipPath, zip.Store, file{"a.txt", "1"}, file{"./a.txt", "2"}, file{"b.txt", "3"})
+
+	tarPath := filepath.Join(dir, "a.tar")
+	writeTar(t, tarPath, file{"a.txt", "1"}, file{"b.txt", "3"}, file{"a.txt", "2"})
+
+	for _, filePath := range []string{zipPath, tarPath} {
+		s := newStorage(t, filePath, 100)
+
+		relPaths, err := s.ListFiles(".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (len(relPaths) != 2) || (relPaths[0] != "a.txt") || (relPaths[1] != "b.txt") {
+			t.Fatalf("%s: unexpected files: %v", filePath, relPaths)
+		}
+
+		data := readFile(t, s, "a.txt")
+		if data != "2" {
+			t.Fatalf("%s: the last file is not used: %q", filePath, data)
+		}
+	}
+}
+
+func Test_ScanFiles_Checksums(t *testing.T) {
+	dir := t.TempDir()
+
+	// Files of both versions have the same sizes and modification times.
+	for _, ext := range []string{".zip", ".tar"} {
+		v1, v2 := filepath.Join(dir, "v1"+ext), filepath.Join(dir, "v2"+ext)
+		if ext == ".zip" {
+			writeZip(t, v1, zip.Deflate, file{"a.txt", "1"}, file{"b.txt", "3"})
+			writeZip(t, v2, zip.Deflate, file{"a.txt", "2"}, file{"b.txt", "3"})
+		} else {
+			writeTar(t, v1, file{"a.txt", "1"}, file{"b.txt", "3"})
+			writeTar(t, v2, file{"a.txt", "2"}, file{"b.txt", "3"})
+		}
+
+		s := newStorage(t, v1, 100)
+		oldStates, err := s.ScanFiles(".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = s.Switch(v2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		newStates, err := s.ScanFiles(".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if oldStates["a.txt"].IsSameAs(newStates["a.txt"]) {
+			t.Fatalf("%s: changed file is not found", ext)
+		}
+		if !oldStates["b.txt"].IsSameAs(newStates["b.txt"]) {
+			t.Fatalf("%s: unchanged file is found", ext)
+		}
+	}
+}
+
+func Test_Reload(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "a.zip")
+	writeZip(t, filePath, zip.Store, file{"a.txt", "1"})
+
+	s := newStorage(t, filePath, 100)
+	oldStates, err := s.ScanFiles(".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isReloaded, err := s.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isReloaded {
+		t.Fatal("unchanged archive is reloaded")
+	}
+
+	// A touched file with the same contents is not reloaded.
+	touch(t, filePath, entryTime.Add(time.Hour))
+	isReloaded, err = s.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isReloaded {
+		t.Fatal("touched archive is reloaded")
+	}
+
+	// The new version has the same size.
+	writeZip(t, filePath, zip.Store, file{"a.txt", "2"})
+	touch(t, filePath, entryTime.Add(time.Hour*2))
+
+	// A scan does not reload the archive.
+	states, err := s.ScanFiles(".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !states["a.txt"].IsSameAs(oldStates["a.txt"]) {
+		t.Fatal("archive is reloaded by a scan")
+	}
+
+	isReloaded, err = s.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isReloaded {
+		t.Fatal("changed archive is not reloaded")
+	}
+
+	data := readFile(t, s, "a.txt")
+	if data != "2" {
+		t.Fatalf("new version is not used: %q", data)
+	}
+}
+
+// touch changes the modification time of the file.
+func touch(tb testing.TB, filePath string, modTime time.Time) {
+	tb.Helper()
+
+	err := os.Chtimes(filePath, modTime, modTime)
+	if err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func Test_OpenFile_LargeCompressedFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "a.zip")
+	expected := bytes.Repeat([]byte("0123456789"), 100)
+	writeZip(t, filePath, zip.Deflate, file{"a.txt", string(expected)})
+
+	// The file is larger than the limit, so it is unpacked while being read.
+	s := newStorage(t, filePath, 100)
+
+	data := readFile(t, s, "a.txt")
+	if data != string(expected) {
+		t.Fatalf("unexpected data of %d bytes", len(data))
+	}
+
+	_, f, fileSize, err := s.OpenFile("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if fileSize != int64(len(expected)) {
+		t.Fatalf("unexpected size: %d", fileSize)
+	}
+
+	buf := make([]byte, 20)
+	n, err := f.ReadAt(buf, 505)
+	if (err != nil) || !bytes.Equal(buf[:n], expected[505:525]) {
+		t.Fatalf("unexpected part: %q, %v", buf[:n], err)
+	}
+
+	n, err = f.ReadAt(buf, 990)
+	if !errors.Is(err, io.EOF) || !bytes.Equal(buf[:n], expected[990:]) {
+		t.Fatalf("unexpected end: %q, %v", buf[:n], err)
+	}
+}
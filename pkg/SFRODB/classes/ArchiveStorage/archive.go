@@ -0,0 +1,416 @@
+package as
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+	ae "github.com/vault-thirteen/auxie/errors"
+)
+
+// Format is a format of an archive.
+type Format byte
+
+const (
+	Format_Unknown = Format(0)
+	Format_Zip     = Format(1)
+	Format_Tar     = Format(2)
+)
+
+// Extensions of archive files.
+const (
+	ExtensionZip = ".zip"
+	ExtensionTar = ".tar"
+)
+
+const ErrArchiveFormatIsUnknown = "unknown format of archive: %s"
+
+// FormatOfPath returns the format of an archive by the extension of its file.
+func FormatOfPath(filePath string) (f Format) {
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ExtensionZip:
+		return Format_Zip
+	case ExtensionTar:
+		return Format_Tar
+	default:
+		return Format_Unknown
+	}
+}
+
+// archive is an opened archive with an index of its files. The archive file
+// is closed when the archive is retired and all its opened files are closed.
+type archive struct {
+	filePath string
+	file     *os.File
+
+	// State of the archive file itself, including the checksum of its
+	// contents. A change of it means that the file has been replaced. It is
+	// changed under the switch lock of the storage.
+	state storage.FileState
+
+	// Compressed files which are larger are not unpacked into memory.
+	inMemorySizeMax int64
+
+	// Files by relative paths, and the sorted paths.
+	entries  map[string]*entry
+	relPaths []string
+
+	// Number of opened files of the archive.
+	users      int
+	isRetired  bool
+	usersLock  *sync.Mutex
+	closeOnce  *sync.Once
+	closeError error
+}
+
+// entry is a file of an archive.
+type entry struct {
+	// State of the file, including the CRC-32 checksum of its contents.
+	state storage.FileState
+
+	// Position of the data of an uncompressed file in the archive file.
+	// Compressed files are opened by the function.
+	offset int64
+	open   func() (io.ReadCloser, error)
+}
+
+// openArchive opens an archive and builds an index of its files. Compressed
+// files up to the size limit are unpacked into memory when they are opened.
+func openArchive(filePath string, inMemorySizeMax int64) (arc *archive, err error) {
+	format := FormatOfPath(filePath)
+	if format == Format_Unknown {
+		return nil, fmt.Errorf(ErrArchiveFormatIsUnknown, filePath)
+	}
+
+	arc = &archive{
+		filePath:        filePath,
+		inMemorySizeMax: inMemorySizeMax,
+		entries:         make(map[string]*entry),
+		usersLock:       new(sync.Mutex),
+		closeOnce:       new(sync.Once),
+	}
+
+	arc.file, err = os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var fi os.FileInfo
+	fi, err = arc.file.Stat()
+	if err != nil {
+		return nil, ae.Combine(err, arc.file.Close())
+	}
+	arc.state = storage.FileState{Size: fi.Size(), ModTime: fi.ModTime()}
+
+	arc.state.Checksum, err = checksumOf(io.NewSectionReader(arc.file, 0, arc.state.Size))
+	if err != nil {
+		return nil, ae.Combine(err, arc.file.Close())
+	}
+
+	switch format {
+	case Format_Zip:
+		err = arc.indexZip()
+	case Format_Tar:
+		err = arc.indexTar()
+	}
+	if err != nil {
+		return nil, ae.Combine(err, arc.file.Close())
+	}
+
+	arc.relPaths = make([]string, 0, len(arc.entries))
+	for relPath := range arc.entries {
+		arc.relPaths = append(arc.relPaths, relPath)
+	}
+	sort.Strings(arc.relPaths)
+
+	return arc, nil
+}
+
+// indexZip builds an index of a ZIP archive.
+func (arc *archive) indexZip() (err error) {
+	var zr *zip.Reader
+	zr, err = zip.NewReader(arc.file, arc.state.Size)
+	if err != nil {
+		return err
+	}
+
+	var e *entry
+	for _, zf := range zr.File {
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+
+		e = &entry{
+			state: storage.FileState{Size: int64(zf.UncompressedSize64), ModTime: zf.Modified, Checksum: zf.CRC32},
+		}
+
+		if zf.Method == zip.Store {
+			e.offset, err = zf.DataOffset()
+			if err != nil {
+				return err
+			}
+		} else {
+			e.open = zf.Open
+		}
+
+		arc.addEntry(zf.Name, e)
+	}
+
+	return nil
+}
+
+// indexTar builds an index of a TAR archive. Data of files is stored in a
+// TAR archive as it is, so each file is a part of the archive file. TAR
+// archives do not store checksums of files, so they are calculated here.
+func (arc *archive) indexTar() (err error) {
+	tr := tar.NewReader(arc.file)
+
+	var hdr *tar.Header
+	for {
+		hdr, err = tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Data of the file starts right after its header.
+		var offset int64
+		offset, err = arc.file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+
+		var checksum uint32
+		checksum, err = checksumOf(tr)
+		if err != nil {
+			return err
+		}
+
+		arc.addEntry(hdr.Name, &entry{
+			state:  storage.FileState{Size: hdr.Size, ModTime: hdr.ModTime, Checksum: checksum},
+			offset: offset,
+		})
+	}
+}
+
+// checksumOf returns the CRC-32 checksum of all the data of the reader.
+func checksumOf(r io.Reader) (checksum uint32, err error) {
+	h := crc32.NewIEEE()
+
+	_, err = io.Copy(h, r)
+	if err != nil {
+		return 0, err
+	}
+
+	return h.Sum32(), nil
+}
+
+// addEntry adds a file to the index. Names of files are cleaned, so that
+// './a/b' and 'a/b' are the same file. Files leading outside the archive are
+// skipped. When the archive has several files with the same path, the last
+// one is used, as when the archive is unpacked.
+func (arc *archive) addEntry(name string, e *entry) {
+	relPath := strings.TrimPrefix(path.Clean("/"+name), "/")
+	if (len(relPath) == 0) || !storage.IsRelPathValid(relPath) {
+		return
+	}
+
+	arc.entries[relPath] = e
+}
+
+// openEntry opens a file of the archive. Uncompressed files are read from the
+// archive file directly. Compressed files are unpacked into memory, unless
+// they are larger than the limit; such files are unpacked while being read.
+// The archive must be acquired by the caller; it is released when the file is
+// closed, or at once when the file is not opened or is unpacked.
+func (arc *archive) openEntry(e *entry) (f storage.File, err error) {
+	if e.open == nil {
+		return &archiveFile{
+			SectionReader: io.NewSectionReader(arc.file, e.offset, e.state.Size),
+			arc:           arc,
+			closeOnce:     new(sync.Once),
+		}, nil
+	}
+
+	if e.state.Size > arc.inMemorySizeMax {
+		return &inflatingFile{
+			arc:       arc,
+			e:         e,
+			closeOnce: new(sync.Once),
+		}, nil
+	}
+
+	defer func() {
+		derr := arc.release()
+		if derr != nil {
+			err = ae.Combine(err, derr)
+		}
+	}()
+
+	var rc io.ReadCloser
+	rc, err = e.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	data, err = io.ReadAll(rc)
+	err = ae.Combine(err, rc.Close())
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.NewBytesFile(data), nil
+}
+
+// acquire registers a user of the archive file. An archive which has been
+// closed can not be used.
+func (arc *archive) acquire() (ok bool) {
+	arc.usersLock.Lock()
+	defer arc.usersLock.Unlock()
+
+	if arc.isRetired && (arc.users == 0) {
+		return false
+	}
+
+	arc.users++
+	return true
+}
+
+// release unregisters a user of the archive file. The file of a retired
+// archive is closed after its last user.
+func (arc *archive) release() (err error) {
+	arc.usersLock.Lock()
+	arc.users--
+	mustClose := arc.isRetired && (arc.users == 0)
+	arc.usersLock.Unlock()
+
+	if mustClose {
+		return arc.close()
+	}
+
+	return nil
+}
+
+// retire marks the archive as not used by the storage any more. The file is
+// closed when it has no users.
+func (arc *archive) retire() (err error) {
+	arc.usersLock.Lock()
+	arc.isRetired = true
+	mustClose := arc.users == 0
+	arc.usersLock.Unlock()
+
+	if mustClose {
+		return arc.close()
+	}
+
+	return nil
+}
+
+// close closes the archive file once.
+func (arc *archive) close() (err error) {
+	arc.closeOnce.Do(func() {
+		arc.closeError = arc.file.Close()
+	})
+
+	return arc.closeError
+}
+
+// archiveFile is an uncompressed file of an archive.
+type archiveFile struct {
+	*io.SectionReader
+	arc       *archive
+	closeOnce *sync.Once
+}
+
+// Close is the standard method of the 'io.Closer' interface. The archive is
+// released only once.
+func (af *archiveFile) Close() (err error) {
+	af.closeOnce.Do(func() {
+		err = af.arc.release()
+	})
+
+	return err
+}
+
+// inflatingFile is a compressed file of an archive which is too large to be
+// unpacked into memory. It is unpacked while being read. Reading at an offset
+// unpacks the file from its start, so it is slower than sequential reading.
+type inflatingFile struct {
+	arc *archive
+	e   *entry
+
+	// Reader of sequential reading, it is opened by the first read.
+	rc io.ReadCloser
+
+	closeOnce *sync.Once
+}
+
+// Read is the standard method of the 'io.Reader' interface.
+func (inf *inflatingFile) Read(p []byte) (n int, err error) {
+	if inf.rc == nil {
+		inf.rc, err = inf.e.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return inf.rc.Read(p)
+}
+
+// ReadAt is the standard method of the 'io.ReaderAt' interface.
+func (inf *inflatingFile) ReadAt(p []byte, off int64) (n int, err error) {
+	if off >= inf.e.state.Size {
+		return 0, io.EOF
+	}
+
+	var rc io.ReadCloser
+	rc, err = inf.e.open()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		err = ae.Combine(err, rc.Close())
+	}()
+
+	_, err = io.CopyN(io.Discard, rc, off)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err = io.ReadFull(rc, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, io.EOF
+	}
+
+	return n, err
+}
+
+// Close is the standard method of the 'io.Closer' interface. The archive is
+// released only once.
+func (inf *inflatingFile) Close() (err error) {
+	inf.closeOnce.Do(func() {
+		if inf.rc != nil {
+			err = inf.rc.Close()
+		}
+
+		err = ae.Combine(err, inf.arc.release())
+	})
+
+	return err
+}
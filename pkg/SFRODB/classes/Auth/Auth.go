@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+const (
+	ErrSecretIsTooShort   = "secret is too short: %v, minimum is %v"
+	ErrChallengeIsInvalid = "challenge is not valid"
+)
+
+const (
+	// ChallengeLen is the length of a challenge sent by server.
+	ChallengeLen = 32
+
+	// SignatureLen is the length of a client's answer to a challenge.
+	SignatureLen = sha256.Size
+
+	// SecretLenMin is the minimal length of a shared secret.
+	SecretLenMin = 16
+)
+
+// Secret is a secret shared by server and its clients. It is never printed.
+type Secret []byte
+
+// String hides the secret from logs.
+func (s Secret) String() string {
+	if len(s) == 0 {
+		return ""
+	}
+
+	return "***"
+}
+
+// Check checks the length of the secret.
+func (s Secret) Check() (err error) {
+	if len(s) < SecretLenMin {
+		return fmt.Errorf(ErrSecretIsTooShort, len(s), SecretLenMin)
+	}
+
+	return nil
+}
+
+// NewChallenge creates a random challenge.
+func NewChallenge() (challenge []byte, err error) {
+	challenge = make([]byte, ChallengeLen)
+
+	_, err = rand.Read(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// Sign answers the challenge, i.e. calculates an HMAC-SHA256 of the challenge
+// using the secret as a key.
+func Sign(secret Secret, challenge []byte) (signature []byte, err error) {
+	if len(challenge) != ChallengeLen {
+		return nil, errors.New(ErrChallengeIsInvalid)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(challenge)
+
+	return mac.Sum(nil), nil
+}
+
+// Verify checks the client's answer to the challenge. Comparison takes
+// constant time.
+func Verify(secret Secret, challenge []byte, signature []byte) (ok bool) {
+	expected, err := Sign(secret, challenge)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(expected, signature)
+}
@@ -1,16 +1,24 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Auth"
 	cs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ClientSettings"
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Connection"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Hello"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Pipeline"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Response"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
-	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/tcp"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/socket"
 	ae "github.com/vault-thirteen/auxie/errors"
 )
 
@@ -27,9 +35,18 @@ const (
 	ErrDoubleStopIsNotPossible   = "double stop is not possible"
 	ErrUnexpectedServerBehaviour = "unexpected server behaviour"
 	ErrClientError               = "client error"
+	ErrHandshakeFailed           = "handshake failed: %s"
+	ErrServerIsIncompatible      = "server is incompatible: protocol version %s, client's version %s"
+	ErrServerLacksFeatures       = "server does not support required features: %s"
+	ErrAuthenticationFailed      = "authentication failed: %s"
 )
 
+// FeaturesRequired are the features without which the client can not work.
+const FeaturesRequired = hello.Feature_RequestIds
+
 // Client is client.
+// Requests are pipelined, so a single client may be used by several goroutines
+// at the same time.
 type Client struct {
 	// All the clients must have unique IDs.
 	id string
@@ -39,11 +56,21 @@ type Client struct {
 	mainDsn string
 	auxDsn  string
 
-	mainAddr *net.TCPAddr
-	auxAddr  *net.TCPAddr
+	// Addresses are either TCP addresses or addresses of Unix sockets.
+	mainAddr net.Addr
+	auxAddr  net.Addr
+
+	// TLS configuration, nil when TLS is disabled.
+	tlsConfig *tls.Config
+
+	mainPipeline *pipeline.Pipeline
+	auxPipeline  *pipeline.Pipeline
 
-	mainConnection *connection.Connection
-	auxConnection  *connection.Connection
+	// Hello messages of the server received at the start of the main and
+	// the auxiliary connections. They are nil when the hello exchange is
+	// disabled.
+	serverHello    *hello.Hello
+	auxServerHello *hello.Hello
 
 	// Internal control structures.
 	startStopLock *sync.Mutex
@@ -59,22 +86,29 @@ func New(stn *cs.ClientSettings, id string) (cli *Client, err error) {
 	cli = &Client{
 		id:            id,
 		settings:      stn,
-		mainDsn:       fmt.Sprintf("%s:%d", stn.Host, stn.MainPort),
-		auxDsn:        fmt.Sprintf("%s:%d", stn.Host, stn.AuxPort),
+		mainDsn:       socket.Dsn(stn.Host, stn.MainPort, stn.MainSocket),
+		auxDsn:        socket.Dsn(stn.Host, stn.AuxPort, stn.AuxSocket),
 		startStopLock: new(sync.Mutex),
 		isWorking:     new(atomic.Bool),
 	}
 
-	cli.mainAddr, err = net.ResolveTCPAddr(protocol.LowLevelProtocol, cli.mainDsn)
+	cli.mainAddr, err = socket.ResolveAddr(stn.Host, stn.MainPort, stn.MainSocket)
 	if err != nil {
 		return nil, err
 	}
 
-	cli.auxAddr, err = net.ResolveTCPAddr(protocol.LowLevelProtocol, cli.auxDsn)
+	cli.auxAddr, err = socket.ResolveAddr(stn.Host, stn.AuxPort, stn.AuxSocket)
 	if err != nil {
 		return nil, err
 	}
 
+	if stn.Tls != nil {
+		cli.tlsConfig, err = stn.Tls.NewClientConfig(stn.Host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return cli, nil
 }
 
@@ -113,8 +147,25 @@ func (cli *Client) start() (cerr *ce.CommonError) {
 
 	cerr = cli.startAuxConnection()
 	if cerr != nil {
+		_ = cli.mainPipeline.Connection().Break()
+		return cerr
+	}
+
+	var serverHello, auxServerHello *hello.Hello
+	serverHello, cerr = cli.handshake(cli.mainPipeline)
+	if cerr == nil {
+		auxServerHello, cerr = cli.handshake(cli.auxPipeline)
+	}
+	if cerr == nil {
+		cerr = cli.authenticate(cli.auxPipeline)
+	}
+	if cerr != nil {
+		_ = cli.mainPipeline.Connection().Break()
+		_ = cli.auxPipeline.Connection().Break()
 		return cerr
 	}
+	cli.serverHello = serverHello
+	cli.auxServerHello = auxServerHello
 
 	cli.isWorking.Store(true)
 
@@ -122,45 +173,179 @@ func (cli *Client) start() (cerr *ce.CommonError) {
 }
 
 func (cli *Client) startMainConnection() (cerr *ce.CommonError) {
-	mainConn, err := net.DialTCP(protocol.LowLevelProtocol, nil, cli.mainAddr)
+	var mainConn net.Conn
+	mainConn, cerr = cli.dial(cli.mainAddr)
+	if cerr != nil {
+		return cerr
+	}
+
+	cli.mainPipeline = pipeline.New(connection.New(mainConn, cli.id))
+
+	return nil
+}
+
+func (cli *Client) startAuxConnection() (cerr *ce.CommonError) {
+	var auxConn net.Conn
+	auxConn, cerr = cli.dial(cli.auxAddr)
+	if cerr != nil {
+		return cerr
+	}
+
+	cli.auxPipeline = pipeline.New(connection.New(auxConn, cli.id))
+
+	return nil
+}
+
+// dial connects to the server. When TLS is enabled, the TLS handshake is
+// performed.
+// Returns a detailed error.
+func (cli *Client) dial(addr net.Addr) (netConn net.Conn, cerr *ce.CommonError) {
+	conn, err := socket.Dial(addr)
 	if err != nil {
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	if cli.tlsConfig == nil {
+		return conn, nil
 	}
 
-	err = tcp.EnableKeepAlives(mainConn, protocol.TcpKeepAliveIsEnabled, protocol.TcpKeepAlivePeriodSec)
+	tlsConn := tls.Client(conn, cli.tlsConfig)
+
+	err = tlsConn.SetDeadline(time.Now().Add(time.Second * protocol.TlsHandshakeTimeoutSec))
+	if err == nil {
+		err = tlsConn.Handshake()
+	}
+	if err == nil {
+		err = tlsConn.SetDeadline(time.Time{})
+	}
 	if err != nil {
-		closeErr := mainConn.Close()
+		closeErr := conn.Close()
 		if closeErr != nil {
 			err = ae.Combine(err, closeErr)
 		}
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
 	}
 
-	cli.mainConnection = connection.New(mainConn, cli.settings.ResponseMessageLengthLimit, cli.id)
+	return tlsConn, nil
+}
 
-	return nil
+// handshake exchanges hello messages with the server and enables features
+// supported by both sides for the connection. Servers which are not compatible
+// are refused.
+// Returns a detailed error.
+func (cli *Client) handshake(p *pipeline.Pipeline) (serverHello *hello.Hello, cerr *ce.CommonError) {
+	if cli.settings.IsHandshakeDisabled {
+		return nil, nil
+	}
+
+	// Size of responses is not limited, large records may be streamed.
+	clientHello := hello.New(0)
+	if !cli.settings.IsCompressionEnabled {
+		clientHello.Features &^= hello.Features_Compression
+	}
+	if cli.tlsConfig == nil {
+		clientHello.Features &^= hello.Feature_Tls
+	}
+
+	var resp *response.Response
+	resp, cerr = cli.request_hello(context.Background(), p, clientHello)
+	if cerr != nil {
+		return nil, ce.NewClientError(fmt.Sprintf(ErrHandshakeFailed, cerr.Error()), 0, 0, cli.id)
+	}
+
+	if resp.Status != status.Status_Hello {
+		return nil, ce.NewClientError(fmt.Sprintf(ErrHandshakeFailed, ErrUnexpectedServerBehaviour), 0, resp.Status, cli.id)
+	}
+
+	var err error
+	serverHello, err = resp.GetHello()
+	if err != nil {
+		return nil, ce.NewClientError(fmt.Sprintf(ErrHandshakeFailed, err.Error()), 0, resp.Status, cli.id)
+	}
+
+	if !clientHello.IsCompatibleWith(serverHello) {
+		return nil, ce.NewClientError(fmt.Sprintf(ErrServerIsIncompatible, serverHello.Version(), clientHello.Version()), 0, resp.Status, cli.id)
+	}
+
+	if !serverHello.Features.Has(FeaturesRequired) {
+		return nil, ce.NewClientError(fmt.Sprintf(ErrServerLacksFeatures, FeaturesRequired&^serverHello.Features), 0, resp.Status, cli.id)
+	}
+
+	p.Connection().SetFeatures(clientHello.Features & serverHello.Features)
+
+	return serverHello, nil
 }
 
-func (cli *Client) startAuxConnection() (cerr *ce.CommonError) {
-	auxConn, err := net.DialTCP(protocol.LowLevelProtocol, nil, cli.auxAddr)
+// authenticate answers the server's challenge using the shared secret. Nothing
+// is done when the secret is not set.
+// Returns a detailed error.
+func (cli *Client) authenticate(p *pipeline.Pipeline) (cerr *ce.CommonError) {
+	if len(cli.settings.AuxSecret) == 0 {
+		return nil
+	}
+
+	var resp *response.Response
+	resp, cerr = cli.request_authChallenge(context.Background(), p)
+	if cerr != nil {
+		return ce.NewClientError(fmt.Sprintf(ErrAuthenticationFailed, cerr.Error()), 0, 0, cli.id)
+	}
+
+	if resp.Status != status.Status_AuthChallenge {
+		return cli.newResponseError(resp)
+	}
+
+	challenge, err := resp.GetChallenge()
 	if err != nil {
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return ce.NewClientError(fmt.Sprintf(ErrAuthenticationFailed, err.Error()), 0, resp.Status, cli.id)
 	}
 
-	err = tcp.EnableKeepAlives(auxConn, protocol.TcpKeepAliveIsEnabled, protocol.TcpKeepAlivePeriodSec)
+	var signature []byte
+	signature, err = auth.Sign(cli.settings.AuxSecret, challenge)
 	if err != nil {
-		closeErr := auxConn.Close()
-		if closeErr != nil {
-			err = ae.Combine(err, closeErr)
-		}
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return ce.NewClientError(fmt.Sprintf(ErrAuthenticationFailed, err.Error()), 0, resp.Status, cli.id)
+	}
+
+	resp, cerr = cli.request_authResponse(context.Background(), p, signature)
+	if cerr != nil {
+		return ce.NewClientError(fmt.Sprintf(ErrAuthenticationFailed, cerr.Error()), 0, 0, cli.id)
 	}
 
-	cli.auxConnection = connection.New(auxConn, cli.settings.ResponseMessageLengthLimit, cli.id)
+	if resp.Status != status.Status_OK {
+		return cli.newResponseError(resp)
+	}
 
 	return nil
 }
 
+// IsBroken tells whether any of the client's connections is broken, e.g.
+// after a call which context was cancelled. A broken client must be
+// restarted.
+func (cli *Client) IsBroken() (isBroken bool) {
+	return cli.mainPipeline.IsBroken() || cli.auxPipeline.IsBroken()
+}
+
+// GetServerHello returns the hello message of the server received when the
+// client was started. It is nil when the hello exchange is disabled.
+func (cli *Client) GetServerHello() (h *hello.Hello) {
+	return cli.serverHello
+}
+
+// IsAuthRequired tells whether the server requires authentication on the
+// auxiliary port. It is known from the hello exchange, so it is false when
+// the exchange is disabled.
+func (cli *Client) IsAuthRequired() (isAuthRequired bool) {
+	if cli.auxServerHello == nil {
+		return false
+	}
+
+	return cli.auxServerHello.Features.Has(hello.Feature_Auth)
+}
+
+// GetFeatures returns the features negotiated with the server.
+func (cli *Client) GetFeatures() (features hello.Feature) {
+	return cli.mainPipeline.Connection().Features()
+}
+
 // Stop stops the client.
 func (cli *Client) Stop() (cerr *ce.CommonError) {
 	cli.startStopLock.Lock()
@@ -169,23 +354,27 @@ func (cli *Client) Stop() (cerr *ce.CommonError) {
 	return cli.stop()
 }
 
+// stop breaks both connections, even when breaking one of them fails. Errors
+// of both connections are combined.
 func (cli *Client) stop() (cerr *ce.CommonError) {
 	if !cli.isWorking.Load() {
 		return ce.NewClientError(ErrDoubleStopIsNotPossible, 0, 0, ClientIdNone)
 	}
 
-	cerr = cli.mainConnection.Break()
-	if cerr != nil {
-		return cerr
-	}
-
-	cerr = cli.auxConnection.Break()
-	if cerr != nil {
-		return cerr
-	}
+	mainErr := cli.mainPipeline.Connection().Break()
+	auxErr := cli.auxPipeline.Connection().Break()
 
 	cli.isWorking.Store(false)
 
+	switch {
+	case (mainErr != nil) && (auxErr != nil):
+		return ce.NewClientError(ae.Combine(mainErr, auxErr).Error(), 0, 0, cli.id)
+	case mainErr != nil:
+		return mainErr
+	case auxErr != nil:
+		return auxErr
+	}
+
 	return nil
 }
 
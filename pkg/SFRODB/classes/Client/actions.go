@@ -1,94 +1,351 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Compression"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Item"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Pipeline"
+	rs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/RecordStat"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Response"
+	si "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerInfo"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Stats"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
 )
 
 // CloseConnection_Main tells the server to close the main connection.
 // Returns a detailed error.
 func (cli *Client) CloseConnection_Main(normalExit bool) (cerr *ce.CommonError) {
-	return cli.closeConnection_any(true, normalExit)
+	return cli.CloseConnection_MainCtx(context.Background(), normalExit)
+}
+
+// CloseConnection_MainCtx is the 'CloseConnection_Main' method which uses the
+// context. When the context is done before the call is finished, the
+// connection is broken.
+// Returns a detailed error.
+func (cli *Client) CloseConnection_MainCtx(ctx context.Context, normalExit bool) (cerr *ce.CommonError) {
+	return cli.closeConnection_any(ctx, true, normalExit)
 }
 
 // CloseConnection_Aux tells the server to close the auxiliary connection.
 // Returns a detailed error.
 func (cli *Client) CloseConnection_Aux(normalExit bool) (cerr *ce.CommonError) {
-	return cli.closeConnection_any(false, normalExit)
+	return cli.CloseConnection_AuxCtx(context.Background(), normalExit)
+}
+
+// CloseConnection_AuxCtx is the 'CloseConnection_Aux' method which uses the
+// context. When the context is done before the call is finished, the
+// connection is broken.
+// Returns a detailed error.
+func (cli *Client) CloseConnection_AuxCtx(ctx context.Context, normalExit bool) (cerr *ce.CommonError) {
+	return cli.closeConnection_any(ctx, false, normalExit)
 }
 
 // closeConnection_any tells the server to close the connection.
 // Returns a detailed error.
-func (cli *Client) closeConnection_any(useMainConnection bool, normalExit bool) (cerr *ce.CommonError) {
+func (cli *Client) closeConnection_any(ctx context.Context, useMainConnection bool, normalExit bool) (cerr *ce.CommonError) {
+	// If we are closing connection due to an error, we do not wait for the
+	// server's response.
+	var resp *response.Response
 	if useMainConnection {
-		cerr = cli.request_closeConnection(cli.mainConnection)
+		resp, cerr = cli.request_closeConnection(ctx, cli.mainPipeline, normalExit)
 	} else {
-		cerr = cli.request_closeConnection(cli.auxConnection)
+		resp, cerr = cli.request_closeConnection(ctx, cli.auxPipeline, normalExit)
 	}
 	if cerr != nil {
 		return cerr
 	}
 
-	// If we are closing connection due to an error, we do not wait for the
-	// server's response.
 	if !normalExit {
 		return nil
 	}
 
-	// Wait for server's response.
-	var resp *response.Response
-	if useMainConnection {
-		resp, cerr = cli.mainConnection.GetResponseMessage()
-	} else {
-		resp, cerr = cli.auxConnection.GetResponseMessage()
+	if resp.Status != status.Status_ClosingConnection {
+		return ce.NewClientError(ErrUnexpectedServerBehaviour, 0, resp.Status, cli.id)
 	}
+
+	return nil
+}
+
+// Ping checks that both connections of the client are alive. Old servers
+// which do not know the ping method report it as unsupported, such a reply
+// also means that the connection is alive.
+// Returns a detailed error.
+func (cli *Client) Ping() (cerr *ce.CommonError) {
+	return cli.PingCtx(context.Background())
+}
+
+// PingCtx is the 'Ping' method which uses the context. When the context is
+// done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) PingCtx(ctx context.Context) (cerr *ce.CommonError) {
+	if cli.settings.PingTimeoutSec > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cli.settings.PingTimeoutSec)*time.Second)
+		defer cancel()
+	}
+
+	cerr = cli.ping(ctx, cli.mainPipeline)
 	if cerr != nil {
 		return cerr
 	}
 
-	if resp.Status != status.Status_ClosingConnection {
-		return ce.NewClientError(ErrUnexpectedServerBehaviour, 0, resp.Status, cli.id)
+	return cli.ping(ctx, cli.auxPipeline)
+}
+
+// ping checks that the connection is alive.
+// Returns a detailed error.
+func (cli *Client) ping(ctx context.Context, p *pipeline.Pipeline) (cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_ping(ctx, p)
+	if cerr != nil {
+		return cerr
 	}
 
-	return nil
+	switch resp.Status {
+	case status.Status_Pong:
+		return nil
+
+	case status.Status_ClientError:
+		cerr = cli.newResponseError(resp)
+		if cerr.GetCode() == ec.ErrorCode_MethodIsNotSupported {
+			return nil
+		}
+		return cerr
+
+	default:
+		return cli.newResponseError(resp)
+	}
 }
 
 // ShowData requests a data record from server and returns it.
 // Returns a detailed error.
 func (cli *Client) ShowData(uid string) (data []byte, cerr *ce.CommonError) {
-	cerr = cli.request_showData(cli.mainConnection, uid)
+	return cli.ShowDataCtx(context.Background(), uid)
+}
+
+// ShowDataCtx is the 'ShowData' method which uses the context. When the
+// context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) ShowDataCtx(ctx context.Context, uid string) (data []byte, cerr *ce.CommonError) {
+	var c compression.Compression
+	data, c, cerr = cli.ShowDataCompressedCtx(ctx, uid)
 	if cerr != nil {
 		return nil, cerr
 	}
 
+	return cli.decompress(c, data)
+}
+
+// ShowDataCompressed requests data from server. When compression is enabled,
+// data may be returned compressed, as it is received from server, so that it
+// could be passed further without decompression, e.g. to an HTTP client.
+// Returns a detailed error.
+func (cli *Client) ShowDataCompressed(uid string) (data []byte, c compression.Compression, cerr *ce.CommonError) {
+	return cli.ShowDataCompressedCtx(context.Background(), uid)
+}
+
+// ShowDataCompressedCtx is the 'ShowDataCompressed' method which uses the
+// context. When the context is done before the call is finished, the
+// connection is broken.
+// Returns a detailed error.
+func (cli *Client) ShowDataCompressedCtx(ctx context.Context, uid string) (data []byte, c compression.Compression, cerr *ce.CommonError) {
 	var resp *response.Response
-	resp, cerr = cli.mainConnection.GetResponseMessage()
+	resp, cerr = cli.request_showData(ctx, cli.mainPipeline, uid)
 	if cerr != nil {
-		return nil, cerr
+		return nil, compression.Compression_None, cerr
 	}
 
-	if resp.Status != status.Status_ShowingData {
-		if resp.Status == status.Status_ClientError {
-			return nil, ce.NewClientError(ErrClientError, 0, resp.Status, cli.id)
+	switch resp.Status {
+	case status.Status_ShowingData:
+		return resp.Data, compression.Compression_None, nil
+
+	case status.Status_ShowingCompressedData:
+		var err error
+		c, data, err = resp.GetCompressedData()
+		if err != nil {
+			return nil, compression.Compression_None, ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
 		}
 
+		return data, c, nil
+
+	default:
+		return nil, compression.Compression_None, cli.newResponseError(resp)
+	}
+}
+
+// ShowDataStream requests a data record from server and returns a reader of
+// its data, so that the record does not have to be held in memory. The reader
+// must be closed by the caller. Other responses of the main connection are not
+// received until the reader is closed.
+// Returns a detailed error.
+func (cli *Client) ShowDataStream(uid string) (data io.ReadCloser, dataSize uint, cerr *ce.CommonError) {
+	return cli.ShowDataStreamCtx(context.Background(), uid)
+}
+
+// ShowDataStreamCtx is the 'ShowDataStream' method which uses the context.
+// When the context is done before the call is finished, the connection is
+// broken. The call is finished when the reader is closed.
+// Returns a detailed error.
+func (cli *Client) ShowDataStreamCtx(ctx context.Context, uid string) (data io.ReadCloser, dataSize uint, cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, data, cerr = cli.request_showDataStream(ctx, cli.mainPipeline, uid)
+	if cerr != nil {
+		return nil, 0, cerr
+	}
+
+	switch resp.Status {
+	case status.Status_ShowingData:
+		return data, resp.DataSize(), nil
+
+	case status.Status_ShowingCompressedData:
+		// Compressed data is not streamed, it is small enough to be cached.
+		var c compression.Compression
+		var ba []byte
+		var err error
+		c, ba, err = resp.GetCompressedData()
+		if err != nil {
+			return nil, 0, ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
+		}
+
+		ba, cerr = cli.decompress(c, ba)
+		if cerr != nil {
+			return nil, 0, cerr
+		}
+
+		return io.NopCloser(bytes.NewReader(ba)), uint(len(ba)), nil
+
+	default:
+		return nil, 0, cli.newResponseError(resp)
+	}
+}
+
+// ShowDataRange requests a part of a data record from server and returns it
+// together with the total size of the record. The part starts at the offset
+// and has the specified length. The part is truncated when it exceeds the end
+// of the record.
+// Returns a detailed error.
+func (cli *Client) ShowDataRange(uid string, offset uint64, length uint64) (data []byte, totalSize uint64, cerr *ce.CommonError) {
+	return cli.ShowDataRangeCtx(context.Background(), uid, offset, length)
+}
+
+// ShowDataRangeCtx is the 'ShowDataRange' method which uses the context. When
+// the context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) ShowDataRangeCtx(ctx context.Context, uid string, offset uint64, length uint64) (data []byte, totalSize uint64, cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_showDataRange(ctx, cli.mainPipeline, uid, offset, length)
+	if cerr != nil {
+		return nil, 0, cerr
+	}
+
+	if resp.Status != status.Status_ShowingDataRange {
+		return nil, 0, cli.newResponseError(resp)
+	}
+
+	var err error
+	totalSize, data, err = resp.GetDataRange()
+	if err != nil {
+		return nil, 0, ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
+	}
+
+	return data, totalSize, nil
+}
+
+// ShowDataIfModified requests data from server when the version token of the
+// data differs from the specified one, which is the token of a copy the
+// client already has. An empty token means that client has no copy. When the
+// data is not modified, no data is returned and 'isModified' is false.
+// Otherwise, the data is returned with its current version token.
+// Returns a detailed error.
+func (cli *Client) ShowDataIfModified(uid string, token []byte) (data []byte, newToken []byte, isModified bool, cerr *ce.CommonError) {
+	return cli.ShowDataIfModifiedCtx(context.Background(), uid, token)
+}
+
+// ShowDataIfModifiedCtx is the 'ShowDataIfModified' method which uses the
+// context. When the context is done before the call is finished, the
+// connection is broken.
+// Returns a detailed error.
+func (cli *Client) ShowDataIfModifiedCtx(ctx context.Context, uid string, token []byte) (data []byte, newToken []byte, isModified bool, cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_showDataIfModified(ctx, cli.mainPipeline, uid, token)
+	if cerr != nil {
+		return nil, nil, false, cerr
+	}
+
+	switch resp.Status {
+	case status.Status_NotModified:
+		return nil, token, false, nil
+
+	case status.Status_ShowingVersionedData:
+		var err error
+		newToken, data, err = resp.GetVersionedData()
+		if err != nil {
+			return nil, nil, false, ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
+		}
+
+		return data, newToken, true, nil
+
+	default:
+		return nil, nil, false, cli.newResponseError(resp)
+	}
+}
+
+// ShowDataMany requests many data records from server in a single request.
+// Items are returned in the order of UIDs, each item has its own status.
+// Returns a detailed error.
+func (cli *Client) ShowDataMany(uids []string) (items []*item.Item, cerr *ce.CommonError) {
+	return cli.ShowDataManyCtx(context.Background(), uids)
+}
+
+// ShowDataManyCtx is the 'ShowDataMany' method which uses the context. When
+// the context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) ShowDataManyCtx(ctx context.Context, uids []string) (items []*item.Item, cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_showDataMany(ctx, cli.mainPipeline, uids)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if resp.Status != status.Status_ShowingDataMany {
+		return nil, cli.newResponseError(resp)
+	}
+
+	var err error
+	items, err = resp.GetItems()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
+	}
+
+	if len(items) != len(uids) {
 		return nil, ce.NewClientError(ErrUnexpectedServerBehaviour, 0, resp.Status, cli.id)
 	}
 
-	return resp.Data, nil
+	for i, itm := range items {
+		itm.UID = uids[i]
+	}
+
+	return items, nil
 }
 
 // SearchRecord asks server to check existence of a data record in cache.
 // Returns a detailed error.
 func (cli *Client) SearchRecord(uid string) (recExists bool, cerr *ce.CommonError) {
-	cerr = cli.request_searchRecord(cli.mainConnection, uid)
-	if cerr != nil {
-		return false, cerr
-	}
+	return cli.SearchRecordCtx(context.Background(), uid)
+}
 
+// SearchRecordCtx is the 'SearchRecord' method which uses the context. When
+// the context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) SearchRecordCtx(ctx context.Context, uid string) (recExists bool, cerr *ce.CommonError) {
 	var resp *response.Response
-	resp, cerr = cli.mainConnection.GetResponseMessage()
+	resp, cerr = cli.request_searchRecord(ctx, cli.mainPipeline, uid)
 	if cerr != nil {
 		return false, cerr
 	}
@@ -101,20 +358,22 @@ func (cli *Client) SearchRecord(uid string) (recExists bool, cerr *ce.CommonErro
 		return false, nil
 
 	default:
-		return false, ce.NewClientError(ErrUnexpectedServerBehaviour, 0, resp.Status, cli.id)
+		return false, cli.newResponseError(resp)
 	}
 }
 
 // SearchFile asks server to check existence of a file.
 // Returns a detailed error.
 func (cli *Client) SearchFile(uid string) (fileExists bool, cerr *ce.CommonError) {
-	cerr = cli.request_searchFile(cli.mainConnection, uid)
-	if cerr != nil {
-		return false, cerr
-	}
+	return cli.SearchFileCtx(context.Background(), uid)
+}
 
+// SearchFileCtx is the 'SearchFile' method which uses the context. When the
+// context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) SearchFileCtx(ctx context.Context, uid string) (fileExists bool, cerr *ce.CommonError) {
 	var resp *response.Response
-	resp, cerr = cli.mainConnection.GetResponseMessage()
+	resp, cerr = cli.request_searchFile(ctx, cli.mainPipeline, uid)
 	if cerr != nil {
 		return false, cerr
 	}
@@ -127,26 +386,91 @@ func (cli *Client) SearchFile(uid string) (fileExists bool, cerr *ce.CommonError
 		return false, nil
 
 	default:
-		return false, ce.NewClientError(ErrUnexpectedServerBehaviour, 0, resp.Status, cli.id)
+		return false, cli.newResponseError(resp)
 	}
 }
 
-// ForgetRecord requests the server to remove a data entry from cache.
+// StatRecord requests metadata of a data record: its size, modification time,
+// hash sum and whether it is cached. Data of the record is not transferred.
 // Returns a detailed error.
-func (cli *Client) ForgetRecord(uid string) (cerr *ce.CommonError) {
-	cerr = cli.request_forgetRecord(cli.auxConnection, uid)
+func (cli *Client) StatRecord(uid string) (stat *rs.RecordStat, cerr *ce.CommonError) {
+	return cli.StatRecordCtx(context.Background(), uid)
+}
+
+// StatRecordCtx is the 'StatRecord' method which uses the context. When the
+// context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) StatRecordCtx(ctx context.Context, uid string) (stat *rs.RecordStat, cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_statRecord(ctx, cli.mainPipeline, uid)
 	if cerr != nil {
-		return cerr
+		return nil, cerr
+	}
+
+	if resp.Status != status.Status_ShowingRecordStat {
+		return nil, cli.newResponseError(resp)
+	}
+
+	var err error
+	stat, err = resp.GetRecordStat()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
+	}
+
+	return stat, nil
+}
+
+// ListRecords requests a page of a sorted list of records which UIDs start
+// with the prefix, an empty prefix lists all the records. The page starts
+// after the cursor, an empty cursor starts the list. Zero limit means the
+// maximum size of a page. When there are more records, the returned cursor
+// is not empty and it must be passed to get the next page.
+// Returns a detailed error.
+func (cli *Client) ListRecords(prefix string, cursor string, limit uint16) (uids []string, nextCursor string, cerr *ce.CommonError) {
+	return cli.ListRecordsCtx(context.Background(), prefix, cursor, limit)
+}
+
+// ListRecordsCtx is the 'ListRecords' method which uses the context. When the
+// context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) ListRecordsCtx(ctx context.Context, prefix string, cursor string, limit uint16) (uids []string, nextCursor string, cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_listRecords(ctx, cli.mainPipeline, prefix, cursor, limit)
+	if cerr != nil {
+		return nil, "", cerr
+	}
+
+	if resp.Status != status.Status_ShowingRecordList {
+		return nil, "", cli.newResponseError(resp)
+	}
+
+	var err error
+	uids, nextCursor, err = resp.GetRecordList()
+	if err != nil {
+		return nil, "", ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
 	}
 
+	return uids, nextCursor, nil
+}
+
+// ForgetRecord requests the server to remove a data entry from cache.
+// Returns a detailed error.
+func (cli *Client) ForgetRecord(uid string) (cerr *ce.CommonError) {
+	return cli.ForgetRecordCtx(context.Background(), uid)
+}
+
+// ForgetRecordCtx is the 'ForgetRecord' method which uses the context. When
+// the context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) ForgetRecordCtx(ctx context.Context, uid string) (cerr *ce.CommonError) {
 	var resp *response.Response
-	resp, cerr = cli.auxConnection.GetResponseMessage()
+	resp, cerr = cli.request_forgetRecord(ctx, cli.auxPipeline, uid)
 	if cerr != nil {
 		return cerr
 	}
 
 	if resp.Status != status.Status_OK {
-		return ce.NewClientError(ErrUnexpectedServerBehaviour, 0, resp.Status, cli.id)
+		return cli.newResponseError(resp)
 	}
 
 	return nil
@@ -155,20 +479,173 @@ func (cli *Client) ForgetRecord(uid string) (cerr *ce.CommonError) {
 // ResetCache requests the server to remove all entries from cache.
 // Returns a detailed error.
 func (cli *Client) ResetCache() (cerr *ce.CommonError) {
-	cerr = cli.request_resetCache(cli.auxConnection)
+	return cli.ResetCacheCtx(context.Background())
+}
+
+// ResetCacheCtx is the 'ResetCache' method which uses the context. When the
+// context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) ResetCacheCtx(ctx context.Context) (cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_resetCache(ctx, cli.auxPipeline)
 	if cerr != nil {
 		return cerr
 	}
 
+	if resp.Status != status.Status_OK {
+		return cli.newResponseError(resp)
+	}
+
+	return nil
+}
+
+// WarmRecords requests the server to load the listed records into cache, so
+// that the first requests for them do not go to disk. The call blocks until
+// the loading is finished, while other calls of the client are processed
+// meanwhile. Loaded records are in cache after the call, failed records are
+// missing, unreadable or too large to be cached.
+// Returns a detailed error.
+func (cli *Client) WarmRecords(uids []string) (loaded int, failed int, cerr *ce.CommonError) {
+	return cli.WarmRecordsCtx(context.Background(), uids)
+}
+
+// WarmRecordsCtx is the 'WarmRecords' method which uses the context. When the
+// context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) WarmRecordsCtx(ctx context.Context, uids []string) (loaded int, failed int, cerr *ce.CommonError) {
 	var resp *response.Response
-	resp, cerr = cli.auxConnection.GetResponseMessage()
+	resp, cerr = cli.request_warmRecords(ctx, cli.auxPipeline, uids)
 	if cerr != nil {
-		return cerr
+		return 0, 0, cerr
 	}
 
-	if resp.Status != status.Status_OK {
+	return cli.getWarmUpResult(resp)
+}
+
+// WarmRecordsWithPrefix requests the server to load into cache all the
+// records which UIDs start with the prefix, an empty prefix means all the
+// records. Loading stops when the cache is full. The call blocks until the
+// loading is finished, as the 'WarmRecords' method does.
+// Returns a detailed error.
+func (cli *Client) WarmRecordsWithPrefix(prefix string) (loaded int, failed int, cerr *ce.CommonError) {
+	return cli.WarmRecordsWithPrefixCtx(context.Background(), prefix)
+}
+
+// WarmRecordsWithPrefixCtx is the 'WarmRecordsWithPrefix' method which uses
+// the context. When the context is done before the call is finished, the
+// connection is broken.
+// Returns a detailed error.
+func (cli *Client) WarmRecordsWithPrefixCtx(ctx context.Context, prefix string) (loaded int, failed int, cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_warmRecordsWithPrefix(ctx, cli.auxPipeline, prefix)
+	if cerr != nil {
+		return 0, 0, cerr
+	}
+
+	return cli.getWarmUpResult(resp)
+}
+
+// GetCacheStats requests statistics of the server's cache.
+// Returns a detailed error.
+func (cli *Client) GetCacheStats() (cs *stats.CacheStats, cerr *ce.CommonError) {
+	return cli.GetCacheStatsCtx(context.Background())
+}
+
+// GetCacheStatsCtx is the 'GetCacheStats' method which uses the context. When
+// the context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) GetCacheStatsCtx(ctx context.Context) (cs *stats.CacheStats, cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_showCacheStats(ctx, cli.auxPipeline)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if resp.Status != status.Status_ShowingCacheStats {
+		return nil, cli.newResponseError(resp)
+	}
+
+	var err error
+	cs, err = resp.GetCacheStats()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
+	}
+
+	return cs, nil
+}
+
+// GetServerInfo requests information about the server: its version, uptime,
+// settings and numbers of open connections. Secrets of the settings are
+// redacted by the server.
+// Returns a detailed error.
+func (cli *Client) GetServerInfo() (info *si.ServerInfo, cerr *ce.CommonError) {
+	return cli.GetServerInfoCtx(context.Background())
+}
+
+// GetServerInfoCtx is the 'GetServerInfo' method which uses the context. When
+// the context is done before the call is finished, the connection is broken.
+// Returns a detailed error.
+func (cli *Client) GetServerInfoCtx(ctx context.Context) (info *si.ServerInfo, cerr *ce.CommonError) {
+	var resp *response.Response
+	resp, cerr = cli.request_showServerInfo(ctx, cli.auxPipeline)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if resp.Status != status.Status_ShowingServerInfo {
+		return nil, cli.newResponseError(resp)
+	}
+
+	var err error
+	info, err = resp.GetServerInfo()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
+	}
+
+	return info, nil
+}
+
+// getWarmUpResult reads a response to a request for a warm-up of cache.
+// Returns a detailed error.
+func (cli *Client) getWarmUpResult(resp *response.Response) (loaded int, failed int, cerr *ce.CommonError) {
+	if resp.Status != status.Status_RecordsWarmed {
+		return 0, 0, cli.newResponseError(resp)
+	}
+
+	l, f, err := resp.GetWarmUpResult()
+	if err != nil {
+		return 0, 0, ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
+	}
+
+	return int(l), int(f), nil
+}
+
+// newResponseError creates an error for a response with an unexpected status.
+// Client's errors reported by server keep their codes and messages.
+func (cli *Client) newResponseError(resp *response.Response) (cerr *ce.CommonError) {
+	if resp.Status != status.Status_ClientError {
 		return ce.NewClientError(ErrUnexpectedServerBehaviour, 0, resp.Status, cli.id)
 	}
 
-	return nil
+	code, msg, err := resp.GetClientError()
+	if err != nil {
+		return ce.NewClientError(err.Error(), 0, resp.Status, cli.id)
+	}
+
+	if len(msg) == 0 {
+		msg = ErrClientError
+	}
+
+	return ce.NewClientErrorWithCode(code, msg, 0, resp.Status, cli.id)
+}
+
+// decompress decompresses data received from server.
+// Returns a detailed error.
+func (cli *Client) decompress(c compression.Compression, compressed []byte) (data []byte, cerr *ce.CommonError) {
+	data, err := c.Decompress(compressed)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, status.Status_ShowingCompressedData, cli.id)
+	}
+
+	return data, nil
 }
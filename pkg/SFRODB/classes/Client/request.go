@@ -1,73 +1,251 @@
 package client
 
 import (
+	"context"
+	"io"
+
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
-	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Connection"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Hello"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Pipeline"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Request"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Response"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
 )
 
-// request_closeConnection asks server to close the connection.
+// request_closeConnection asks server to close the connection. When
+// 'mustWait' is set, it waits for the server's response.
 // Returns a detailed error.
-func (cli *Client) request_closeConnection(con *connection.Connection) (cerr *ce.CommonError) {
+func (cli *Client) request_closeConnection(ctx context.Context, p *pipeline.Pipeline, mustWait bool) (resp *response.Response, cerr *ce.CommonError) {
 	req, err := request.New_CloseConnection()
 	if err != nil {
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	if !mustWait {
+		return nil, p.SendCtx(ctx, req)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_hello tells server about the client and asks server about itself.
+// Returns a detailed error.
+func (cli *Client) request_hello(ctx context.Context, p *pipeline.Pipeline, h *hello.Hello) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_Hello(h)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_ping checks that the connection is alive.
+// Returns a detailed error.
+func (cli *Client) request_ping(ctx context.Context, p *pipeline.Pipeline) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_Ping()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_authChallenge asks server for a challenge.
+// Returns a detailed error.
+func (cli *Client) request_authChallenge(ctx context.Context, p *pipeline.Pipeline) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_AuthChallenge()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
 	}
 
-	return con.SendRequestMessage(req)
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_authResponse sends the answer to a challenge to server.
+// Returns a detailed error.
+func (cli *Client) request_authResponse(ctx context.Context, p *pipeline.Pipeline, signature []byte) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_AuthResponse(signature)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
 }
 
 // request_showData asks server for data.
 // Returns a detailed error.
-func (cli *Client) request_showData(con *connection.Connection, uid string) (cerr *ce.CommonError) {
+func (cli *Client) request_showData(ctx context.Context, p *pipeline.Pipeline, uid string) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_ShowData(uid)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_showDataStream asks server for data which is streamed.
+// Returns a detailed error.
+func (cli *Client) request_showDataStream(ctx context.Context, p *pipeline.Pipeline, uid string) (resp *response.Response, data io.ReadCloser, cerr *ce.CommonError) {
 	req, err := request.New_ShowData(uid)
 	if err != nil {
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return nil, nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripStreamCtx(ctx, req, status.Status_ShowingData)
+}
+
+// request_showDataRange asks server for a part of data.
+// Returns a detailed error.
+func (cli *Client) request_showDataRange(ctx context.Context, p *pipeline.Pipeline, uid string, offset uint64, length uint64) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_ShowDataRange(uid, offset, length)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_showDataIfModified asks server for data when its version differs
+// from the specified one.
+// Returns a detailed error.
+func (cli *Client) request_showDataIfModified(ctx context.Context, p *pipeline.Pipeline, uid string, token []byte) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_ShowDataIfModified(uid, token)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_showDataMany asks server for many data records.
+// Returns a detailed error.
+func (cli *Client) request_showDataMany(ctx context.Context, p *pipeline.Pipeline, uids []string) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_ShowDataMany(uids)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
 	}
 
-	return con.SendRequestMessage(req)
+	return p.RoundTripCtx(ctx, req)
 }
 
 // request_searchRecord asks server to check existence of a record in cache.
 // Returns a detailed error.
-func (cli *Client) request_searchRecord(con *connection.Connection, uid string) (cerr *ce.CommonError) {
+func (cli *Client) request_searchRecord(ctx context.Context, p *pipeline.Pipeline, uid string) (resp *response.Response, cerr *ce.CommonError) {
 	req, err := request.New_SearchRecord(uid)
 	if err != nil {
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
 	}
 
-	return con.SendRequestMessage(req)
+	return p.RoundTripCtx(ctx, req)
 }
 
 // request_searchFile asks server to check existence of a file.
 // Returns a detailed error.
-func (cli *Client) request_searchFile(con *connection.Connection, uid string) (cerr *ce.CommonError) {
+func (cli *Client) request_searchFile(ctx context.Context, p *pipeline.Pipeline, uid string) (resp *response.Response, cerr *ce.CommonError) {
 	req, err := request.New_SearchFile(uid)
 	if err != nil {
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_statRecord asks server for metadata of a data record.
+// Returns a detailed error.
+func (cli *Client) request_statRecord(ctx context.Context, p *pipeline.Pipeline, uid string) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_StatRecord(uid)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_listRecords asks server for a page of a list of records.
+// Returns a detailed error.
+func (cli *Client) request_listRecords(ctx context.Context, p *pipeline.Pipeline, prefix string, cursor string, limit uint16) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_ListRecords(prefix, cursor, limit)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_watch asks server to send events about changes.
+// Returns a detailed error.
+func (cli *Client) request_watch(p *pipeline.Pipeline) (messages <-chan *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_Watch()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
 	}
 
-	return con.SendRequestMessage(req)
+	return p.Subscribe(req)
 }
 
 // request_forgetRecord asks server to remove a record from cache.
 // Returns a detailed error.
-func (cli *Client) request_forgetRecord(con *connection.Connection, uid string) (cerr *ce.CommonError) {
+func (cli *Client) request_forgetRecord(ctx context.Context, p *pipeline.Pipeline, uid string) (resp *response.Response, cerr *ce.CommonError) {
 	req, err := request.New_ForgetRecord(uid)
 	if err != nil {
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
 	}
 
-	return con.SendRequestMessage(req)
+	return p.RoundTripCtx(ctx, req)
 }
 
 // request_resetCache asks server to remove all records from cache.
 // Returns a detailed error.
-func (cli *Client) request_resetCache(con *connection.Connection) (cerr *ce.CommonError) {
+func (cli *Client) request_resetCache(ctx context.Context, p *pipeline.Pipeline) (resp *response.Response, cerr *ce.CommonError) {
 	req, err := request.New_ResetCache()
 	if err != nil {
-		return ce.NewClientError(err.Error(), 0, 0, cli.id)
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_warmRecords asks server to load the listed records into cache.
+// Returns a detailed error.
+func (cli *Client) request_warmRecords(ctx context.Context, p *pipeline.Pipeline, uids []string) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_WarmRecords(uids)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_warmRecordsWithPrefix asks server to load the records which UIDs
+// start with the prefix into cache.
+// Returns a detailed error.
+func (cli *Client) request_warmRecordsWithPrefix(ctx context.Context, p *pipeline.Pipeline, prefix string) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_WarmRecordsWithPrefix(prefix)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_showCacheStats asks server to show statistics of its cache.
+// Returns a detailed error.
+func (cli *Client) request_showCacheStats(ctx context.Context, p *pipeline.Pipeline) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_ShowCacheStats()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
+	}
+
+	return p.RoundTripCtx(ctx, req)
+}
+
+// request_showServerInfo asks server to show information about itself.
+// Returns a detailed error.
+func (cli *Client) request_showServerInfo(ctx context.Context, p *pipeline.Pipeline) (resp *response.Response, cerr *ce.CommonError) {
+	req, err := request.New_ShowServerInfo()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, cli.id)
 	}
 
-	return con.SendRequestMessage(req)
+	return p.RoundTripCtx(ctx, req)
 }
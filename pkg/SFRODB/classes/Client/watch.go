@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"log"
+	"net"
+
+	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Connection"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Event"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Pipeline"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Response"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
+)
+
+const (
+	ErrWatchHasStopped = "watch has stopped"
+)
+
+// Watch subscribes to changes on server. Events come when a file of a record
+// is changed, when a record is forgotten and when the cache is reset. Each
+// watch uses its own connection to the main port, so the client must be
+// started, but the watch does not depend on the client's connections.
+//
+// The channel of events is closed when the context is done or when the
+// connection is lost. Events which happen while there is no watch are not
+// delivered, so after a new subscription all the copies of records should be
+// treated as possibly outdated.
+// Returns a detailed error.
+func (cli *Client) Watch(ctx context.Context) (events <-chan *event.Event, cerr *ce.CommonError) {
+	var netConn net.Conn
+	netConn, cerr = cli.dial(cli.mainAddr)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	p := pipeline.New(connection.New(netConn, cli.id))
+
+	// The connection is used only by the watch.
+	stopBreaker := context.AfterFunc(ctx, func() {
+		_ = p.Connection().Break()
+	})
+
+	var messages <-chan *response.Response
+	messages, cerr = cli.subscribe(p)
+	if cerr != nil {
+		stopBreaker()
+		_ = p.Connection().Break()
+		if ctx.Err() != nil {
+			return nil, ce.NewClientError(ctx.Err().Error(), 0, 0, cli.id)
+		}
+		return nil, cerr
+	}
+
+	// The context does not need to break the connection after the watch
+	// has stopped on its own.
+	ch := make(chan *event.Event)
+	go func() {
+		defer stopBreaker()
+		cli.runWatch(ctx, p, messages, ch)
+	}()
+
+	return ch, nil
+}
+
+// subscribe performs the hello exchange on the watch's connection and starts
+// the watch. The first message of a watch confirms the subscription.
+// Returns a detailed error.
+func (cli *Client) subscribe(p *pipeline.Pipeline) (messages <-chan *response.Response, cerr *ce.CommonError) {
+	_, cerr = cli.handshake(p)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	messages, cerr = cli.request_watch(p)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	resp, ok := <-messages
+	if !ok {
+		return nil, ce.NewClientError(ErrWatchHasStopped, 0, 0, cli.id)
+	}
+
+	if resp.Status != status.Status_OK {
+		return nil, cli.newResponseError(resp)
+	}
+
+	return messages, nil
+}
+
+// runWatch delivers events of the watch until the context is done or the
+// connection is lost.
+func (cli *Client) runWatch(ctx context.Context, p *pipeline.Pipeline, messages <-chan *response.Response, events chan *event.Event) {
+	defer close(events)
+	defer func() {
+		_ = p.Connection().Break()
+	}()
+
+	var e *event.Event
+	var err error
+	for resp := range messages {
+		if resp.Status != status.Status_Event {
+			log.Println(cli.newResponseError(resp))
+			return
+		}
+
+		e, err = resp.GetEvent()
+		if err != nil {
+			log.Println(ce.NewClientError(err.Error(), 0, resp.Status, cli.id))
+			return
+		}
+
+		select {
+		case events <- e:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
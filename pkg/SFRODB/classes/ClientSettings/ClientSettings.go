@@ -2,14 +2,16 @@ package cs
 
 import (
 	"errors"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Auth"
+	ts "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/TlsSettings"
 )
 
-const ResponseMessageLengthLimitDefault = 1_000_000 // 1 MB.
+const PingTimeoutSecDefault = 5
 
 const (
-	ErrClientHostIsNotSet         = "client host is not set"
-	ErrClientPortIsNotSet         = "client port is not set"
-	ErrResponseMessageLengthLimit = "response message length limit is not set"
+	ErrClientHostIsNotSet = "client host is not set"
+	ErrClientPortIsNotSet = "client port is not set"
 )
 
 // ClientSettings is client's Settings.
@@ -23,46 +25,74 @@ type ClientSettings struct {
 	// Auxiliary port.
 	AuxPort uint16
 
-	// Maximum size for server's messages.
-	ResponseMessageLengthLimit uint
+	// Paths to Unix sockets of the server which are used instead of the
+	// ports when they are set.
+	MainSocket string
+	AuxSocket  string
+
+	// Time to wait for an answer to a ping. Zero means no limit.
+	PingTimeoutSec uint
+
+	// Enables compression of data responses when server supports it. Data is
+	// decompressed by the client transparently. The hello exchange is
+	// required for compression.
+	IsCompressionEnabled bool
+
+	// TLS settings. TLS is disabled when they are not set.
+	Tls *ts.TlsSettings
+
+	// Secret shared with the server for authentication on the auxiliary
+	// port. Client authenticates itself when it is set.
+	AuxSecret auth.Secret
+
+	// Disables the hello exchange at the start of connections. It is needed
+	// only for old servers which do not support the exchange.
+	IsHandshakeDisabled bool
 }
 
 func New(
 	host string,
 	mainPort uint16,
 	auxPort uint16,
-	responseMessageLengthLimit uint,
 ) (stn *ClientSettings, err error) {
 	stn = &ClientSettings{
-		Host:     host,
-		MainPort: mainPort,
-		AuxPort:  auxPort,
-	}
-
-	stn.ResponseMessageLengthLimit = responseMessageLengthLimit
-
-	if stn.ResponseMessageLengthLimit == 0 {
-		stn.ResponseMessageLengthLimit = ResponseMessageLengthLimitDefault
+		Host:           host,
+		MainPort:       mainPort,
+		AuxPort:        auxPort,
+		PingTimeoutSec: PingTimeoutSecDefault,
 	}
 
 	return stn, nil
 }
 
 func (stn *ClientSettings) Check() (err error) {
-	if len(stn.Host) == 0 {
-		return errors.New(ErrClientHostIsNotSet)
+	// Host name is not needed for Unix sockets.
+	if (len(stn.MainSocket) == 0) || (len(stn.AuxSocket) == 0) {
+		if len(stn.Host) == 0 {
+			return errors.New(ErrClientHostIsNotSet)
+		}
 	}
 
-	if stn.MainPort == 0 {
+	if (stn.MainPort == 0) && (len(stn.MainSocket) == 0) {
 		return errors.New(ErrClientPortIsNotSet)
 	}
 
-	if stn.AuxPort == 0 {
+	if (stn.AuxPort == 0) && (len(stn.AuxSocket) == 0) {
 		return errors.New(ErrClientPortIsNotSet)
 	}
 
-	if stn.ResponseMessageLengthLimit == 0 {
-		return errors.New(ErrResponseMessageLengthLimit)
+	if stn.Tls != nil {
+		err = stn.Tls.CheckForClient()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(stn.AuxSecret) > 0 {
+		err = stn.AuxSecret.Check()
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
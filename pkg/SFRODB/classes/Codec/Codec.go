@@ -0,0 +1,315 @@
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Method"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Request"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Response"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
+	uid "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/UID"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
+)
+
+const (
+	// ReadChunkSizeMax is the maximum size of a buffer allocated at once when
+	// data is read. Larger data is collected piece by piece while it comes,
+	// so that a crafted size of a message does not allocate much memory.
+	ReadChunkSizeMax = 64 * 1024
+)
+
+// RequestError is an error of a request which has been received completely,
+// but which is not valid. Such an error may be reported to the client, the
+// connection may be used further.
+type RequestError struct {
+	Code ec.ErrorCode
+	Msg  string
+}
+
+func (e *RequestError) Error() string {
+	return e.Msg
+}
+
+// EncodeRequest writes the request into the writer. When the size of the
+// request is not set, it is calculated.
+func EncodeRequest(w io.Writer, req *request.Request) (err error) {
+	var ba []byte
+
+	// 1. Size.
+	if req.Size == 0 {
+		rs := protocol.MethodNameLen + protocol.RequestIdLen + protocol.UidSizeLen + uidLength(req.UID) + len(req.Parameters)
+		if rs > math.MaxUint16 {
+			return fmt.Errorf(request.ErrSizeIsTooLong, rs)
+		}
+		req.Size = uint16(rs)
+	}
+
+	order, err := protocol.Endianness.ByteOrder()
+	if err != nil {
+		return err
+	}
+
+	ba = order.AppendUint16(ba, req.Size)
+
+	// 2. Method.
+	var methodBytes []byte
+	methodBytes, err = req.Method.Bytes()
+	if err != nil {
+		return err
+	}
+	ba = append(ba, methodBytes...)
+
+	// 3. Request ID.
+	ba = order.AppendUint32(ba, req.Id)
+
+	// 4. UID size and UID.
+	if req.UID != nil {
+		ba = append(ba, byte(req.UID.Length()))
+		ba = append(ba, req.UID.Bytes()...)
+	} else {
+		ba = append(ba, 0)
+	}
+
+	// 5. Parameters.
+	ba = append(ba, req.Parameters...)
+
+	_, err = w.Write(ba)
+	return err
+}
+
+// DecodeRequest reads a request from the reader. When the request is read
+// completely, but it is not valid, the request is returned together with a
+// '*RequestError' error. Other errors mean that the reader can not be used
+// any more.
+func DecodeRequest(r io.Reader) (req *request.Request, err error) {
+	req = &request.Request{}
+	var ba []byte
+	var requestErr *RequestError
+
+	order, err := protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Size.
+	ba, err = ReadExact(r, protocol.RequestSizeLen)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Size = order.Uint16(ba)
+	if req.Size < protocol.MethodNameLen+protocol.RequestIdLen+protocol.UidSizeLen {
+		return nil, fmt.Errorf(request.ErrSizeIsTooShort, req.Size)
+	}
+
+	// 2. Method.
+	ba, err = ReadExact(r, protocol.MethodNameLen)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rest of the message is read anyway, so that the error could be
+	// reported to the client.
+	req.Method, err = method.NewFromString(string(ba))
+	if err != nil {
+		requestErr = &RequestError{Code: ec.ErrorCode_MethodIsNotSupported, Msg: err.Error()}
+	}
+
+	// 3. Request ID.
+	ba, err = ReadExact(r, protocol.RequestIdLen)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Id = order.Uint32(ba)
+
+	// 4. UID size, UID and parameters. The rest is at least one byte long,
+	// see the check of the size above.
+	restSize := uint(req.Size) - uint(protocol.MethodNameLen) - uint(protocol.RequestIdLen)
+	ba, err = ReadExact(r, restSize)
+	if err != nil {
+		return nil, err
+	}
+
+	uidSize := uint(ba[0])
+	if protocol.UidSizeLen+uidSize > restSize {
+		return nil, fmt.Errorf(request.ErrUidSizeIsTooLong, uidSize)
+	}
+
+	req.UID, err = uid.New(string(ba[protocol.UidSizeLen : protocol.UidSizeLen+uidSize]))
+	if (err != nil) && (requestErr == nil) {
+		requestErr = &RequestError{Code: ec.ErrorCode_UidIsNotValid, Msg: err.Error()}
+	}
+
+	if protocol.UidSizeLen+uidSize < restSize {
+		req.Parameters = ba[protocol.UidSizeLen+uidSize:]
+	}
+
+	if requestErr != nil {
+		return req, requestErr
+	}
+
+	return req, nil
+}
+
+// EncodeResponse writes the response with its data into the writer. When the
+// size of the response is not set, it is calculated.
+func EncodeResponse(w io.Writer, resp *response.Response) (err error) {
+	var buf bytes.Buffer
+	err = EncodeResponseHeader(&buf, resp, len(resp.Data))
+	if err != nil {
+		return err
+	}
+
+	buf.Write(resp.Data)
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// EncodeResponseHeader writes all the parts of the response except its data
+// into the writer. When the size of the response is not set, it is
+// calculated using the size of the data.
+func EncodeResponseHeader(w io.Writer, resp *response.Response, dataSize int) (err error) {
+	var ba []byte
+
+	// 1. Size.
+	if resp.Size == 0 {
+		rs := uint64(protocol.StatusNameLen) + uint64(protocol.RequestIdLen) + uint64(dataSize)
+		if rs > math.MaxUint32 {
+			return fmt.Errorf(response.ErrSizeIsTooLong, rs)
+		}
+		resp.Size = uint32(rs)
+	}
+
+	order, err := protocol.Endianness.ByteOrder()
+	if err != nil {
+		return err
+	}
+
+	ba = order.AppendUint32(ba, resp.Size)
+
+	// 2. Status.
+	var statusBytes []byte
+	statusBytes, err = resp.Status.Bytes()
+	if err != nil {
+		return err
+	}
+	ba = append(ba, statusBytes...)
+
+	// 3. Request ID.
+	ba = order.AppendUint32(ba, resp.RequestId)
+
+	_, err = w.Write(ba)
+	return err
+}
+
+// DecodeResponse reads a response with its data from the reader.
+func DecodeResponse(r io.Reader) (resp *response.Response, err error) {
+	resp, err = DecodeResponseHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = DecodeResponseData(r, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// DecodeResponseHeader reads a response without its data from the reader.
+// Data must be read after the header, e.g. using the 'DecodeResponseData'
+// function.
+func DecodeResponseHeader(r io.Reader) (resp *response.Response, err error) {
+	resp = &response.Response{}
+	var ba []byte
+
+	order, err := protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Size.
+	ba, err = ReadExact(r, protocol.ResponseSizeLen)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Size = order.Uint32(ba)
+	if resp.Size < protocol.StatusNameLen+protocol.RequestIdLen {
+		return nil, fmt.Errorf(response.ErrSizeIsTooShort, resp.Size)
+	}
+
+	// 2. Status.
+	ba, err = ReadExact(r, protocol.StatusNameLen)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Status, err = status.NewFromString(string(ba))
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Request ID.
+	ba, err = ReadExact(r, protocol.RequestIdLen)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.RequestId = order.Uint32(ba)
+
+	return resp, nil
+}
+
+// DecodeResponseData reads data of the response after its header.
+func DecodeResponseData(r io.Reader, resp *response.Response) (err error) {
+	dataSize := resp.DataSize()
+	if dataSize == 0 {
+		return nil
+	}
+
+	resp.Data, err = ReadExact(r, dataSize)
+	return err
+}
+
+// ReadExact reads exactly the specified number of bytes from the reader.
+// When the reader ends earlier, an error is returned.
+func ReadExact(r io.Reader, size uint) (data []byte, err error) {
+	if size <= ReadChunkSizeMax {
+		data = make([]byte, size)
+		_, err = io.ReadFull(r, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	var n int64
+	n, err = io.Copy(&buf, io.LimitReader(r, int64(size)))
+	if err != nil {
+		return nil, err
+	}
+	if n != int64(size) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return buf.Bytes(), nil
+}
+
+// uidLength returns the length of the UID, nil UID is empty.
+func uidLength(u *uid.UID) int {
+	if u == nil {
+		return 0
+	}
+
+	return u.Length()
+}
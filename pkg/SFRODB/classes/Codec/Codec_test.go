@@ -0,0 +1,132 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Request"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Response"
+)
+
+// requestEncoder returns a function which encodes a created request. The
+// test fails when the request is not created or encoded.
+func requestEncoder(tb testing.TB) func(req *request.Request, err error) []byte {
+	return func(req *request.Request, err error) []byte {
+		tb.Helper()
+
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		err = EncodeRequest(&buf, req)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		return buf.Bytes()
+	}
+}
+
+// responseEncoder returns a function which encodes a created response. The
+// test fails when the response is not created or encoded.
+func responseEncoder(tb testing.TB) func(resp *response.Response, err error) []byte {
+	return func(resp *response.Response, err error) []byte {
+		tb.Helper()
+
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		err = EncodeResponse(&buf, resp)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		return buf.Bytes()
+	}
+}
+
+func FuzzDecodeRequest(f *testing.F) {
+	encodedRequest := requestEncoder(f)
+	f.Add(encodedRequest(request.New_Ping()))
+	f.Add(encodedRequest(request.New_ShowData("abc")))
+	f.Add(encodedRequest(request.New_ShowDataRange("abc", 1, 2)))
+	f.Add(encodedRequest(request.New_ShowDataMany([]string{"a", "b"})))
+	f.Add([]byte{0, 8, 'C', 'S', 'D', 0, 0, 0, 1, 0})
+	f.Add([]byte{0, 8, 'C', 'S', 'D', 0, 0, 0, 1, 255})
+	f.Add([]byte{0, 9, 'X', 'X', 'X', 0, 0, 0, 1, 1, '.'})
+	f.Add([]byte{0, 7, 'C', 'S', 'D', 0, 0, 0, 1})
+	f.Add([]byte{255, 255})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		req, err := DecodeRequest(bytes.NewReader(data))
+		if err != nil {
+			var requestErr *RequestError
+			if errors.As(err, &requestErr) && (req == nil) {
+				t.Fatal("request is not returned with its error")
+			}
+			return
+		}
+
+		// A valid request must survive a round trip.
+		req.Size = 0
+		var buf bytes.Buffer
+		err = EncodeRequest(&buf, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var req2 *request.Request
+		req2, err = DecodeRequest(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if (req2.Method != req.Method) ||
+			(req2.Id != req.Id) ||
+			(*req2.UID != *req.UID) ||
+			!bytes.Equal(req2.Parameters, req.Parameters) {
+			t.Fatalf("round trip mismatch: %+v vs %+v", req, req2)
+		}
+	})
+}
+
+func FuzzDecodeResponse(f *testing.F) {
+	encodedResponse := responseEncoder(f)
+	f.Add(encodedResponse(response.New_Pong(1)))
+	f.Add(encodedResponse(response.New_ShowingData(2, []byte("data"))))
+	f.Add(encodedResponse(response.New_ShowingDataRange(3, 10, []byte("da"))))
+	f.Add([]byte{0, 0, 0, 6, 'S', 'O', 'K', 0, 0, 0})
+	f.Add([]byte{255, 255, 255, 255, 'S', 'O', 'K', 0, 0, 0, 1})
+	f.Add([]byte{0, 0, 0, 7, 'X', 'X', 'X', 0, 0, 0, 1})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		resp, err := DecodeResponse(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		// A valid response must survive a round trip.
+		resp.Size = 0
+		var buf bytes.Buffer
+		err = EncodeResponse(&buf, resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var resp2 *response.Response
+		resp2, err = DecodeResponse(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if (resp2.Status != resp.Status) ||
+			(resp2.RequestId != resp.RequestId) ||
+			!bytes.Equal(resp2.Data, resp.Data) {
+			t.Fatalf("round trip mismatch: %+v vs %+v", resp, resp2)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package ce
 
 import (
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonErrorType"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Method"
 	status "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
 )
@@ -9,6 +10,7 @@ import (
 // CommonError is a common error used by the service.
 type CommonError struct {
 	typé     cet.CommonErrorType
+	code     ec.ErrorCode
 	text     string
 	method   method.Method
 	status   status.Status
@@ -49,10 +51,30 @@ func NewClientError(
 	return newCommonError(cet.CommonErrorType_Client, msg, method, status, clientId)
 }
 
+// NewClientErrorWithCode creates a client's error with a code which is
+// reported to the client by server.
+func NewClientErrorWithCode(
+	code ec.ErrorCode,
+	msg string,
+	method method.Method,
+	status status.Status,
+	clientId string,
+) (ce *CommonError) {
+	ce = newCommonError(cet.CommonErrorType_Client, msg, method, status, clientId)
+	ce.code = code
+	return ce
+}
+
 func (ce *CommonError) Error() string {
 	return ce.text
 }
 
+// GetCode returns the code of the error. Errors which are not reported by
+// server have no code.
+func (ce *CommonError) GetCode() ec.ErrorCode {
+	return ce.code
+}
+
 func (ce *CommonError) GetMethod() method.Method {
 	return ce.method
 }
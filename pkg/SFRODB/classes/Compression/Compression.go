@@ -0,0 +1,112 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+
+	ae "github.com/vault-thirteen/auxie/errors"
+)
+
+const (
+	ErrUnknownCompression = "unknown compression: %v"
+)
+
+// Compression is a method of compression of data. Names of the methods are
+// the same as the names of HTTP content codings, so that compressed data may
+// be passed to HTTP clients as is.
+type Compression byte
+
+const (
+	Compression_None    = Compression(0)
+	Compression_Gzip    = Compression(1)
+	Compression_Deflate = Compression(2)
+)
+
+const (
+	Name_None    = "identity"
+	Name_Gzip    = "gzip"
+	Name_Deflate = "deflate"
+)
+
+func NewFromByte(b byte) (c Compression, err error) {
+	c = Compression(b)
+
+	switch c {
+	case Compression_None,
+		Compression_Gzip,
+		Compression_Deflate:
+		return c, nil
+
+	default:
+		return Compression_None, fmt.Errorf(ErrUnknownCompression, b)
+	}
+}
+
+func (c Compression) String() string {
+	switch c {
+	case Compression_None:
+		return Name_None
+	case Compression_Gzip:
+		return Name_Gzip
+	case Compression_Deflate:
+		return Name_Deflate
+	default:
+		return strconv.Itoa(int(c))
+	}
+}
+
+// Compress compresses the data.
+func (c Compression) Compress(data []byte) (compressed []byte, err error) {
+	var buf = new(bytes.Buffer)
+	var w io.WriteCloser
+
+	switch c {
+	case Compression_None:
+		return data, nil
+	case Compression_Gzip:
+		w = gzip.NewWriter(buf)
+	case Compression_Deflate:
+		w = zlib.NewWriter(buf)
+	default:
+		return nil, fmt.Errorf(ErrUnknownCompression, byte(c))
+	}
+
+	_, err = w.Write(data)
+	err = ae.Combine(err, w.Close())
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses the data.
+func (c Compression) Decompress(compressed []byte) (data []byte, err error) {
+	var r io.ReadCloser
+
+	switch c {
+	case Compression_None:
+		return compressed, nil
+	case Compression_Gzip:
+		r, err = gzip.NewReader(bytes.NewReader(compressed))
+	case Compression_Deflate:
+		r, err = zlib.NewReader(bytes.NewReader(compressed))
+	default:
+		return nil, fmt.Errorf(ErrUnknownCompression, byte(c))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = io.ReadAll(r)
+	err = ae.Combine(err, r.Close())
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -2,37 +2,65 @@ package connection
 
 import (
 	"bytes"
-	"encoding/binary"
+	"errors"
 	"fmt"
-	"math"
+	"io"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Codec"
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
-	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Endianness"
-	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Method"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Hello"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Request"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Response"
-	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
-	uid "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/UID"
-	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
-	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/tcp"
+	ae "github.com/vault-thirteen/auxie/errors"
+)
+
+const (
+	ErrDataSizeMismatch   = "data size mismatch: %v vs %v"
+	ErrResponseIsTooLarge = "response is too large: %v, limit is %v"
 )
 
 type Connection struct {
-	netConn                    *net.TCPConn
-	responseMessageLengthLimit uint
-	clientId                   string
+	netConn  net.Conn
+	clientId string
+
+	// Messages may be sent by several goroutines at the same time, so each
+	// message must be written in one piece.
+	sendLock *sync.Mutex
+	isBroken *atomic.Bool
+
+	// Features negotiated by the hello exchange. A connection without the
+	// exchange has no optional features.
+	features *atomic.Uint32
+
+	// Maximum size of a response accepted by the other side. It is known
+	// after the hello exchange, zero means no limit.
+	responseSizeMax *atomic.Uint32
+
+	// Authentication state. Challenge is the last challenge sent by server,
+	// it may be answered only once.
+	authLock        *sync.Mutex
+	challenge       []byte
+	isAuthenticated *atomic.Bool
 }
 
 func New(
-	netConn *net.TCPConn,
-	responseMessageLengthLimit uint,
+	netConn net.Conn,
 	clientId string,
 ) (con *Connection) {
 	return &Connection{
-		netConn:                    netConn,
-		responseMessageLengthLimit: responseMessageLengthLimit,
-		clientId:                   clientId,
+		netConn:         netConn,
+		clientId:        clientId,
+		sendLock:        new(sync.Mutex),
+		isBroken:        new(atomic.Bool),
+		features:        new(atomic.Uint32),
+		responseSizeMax: new(atomic.Uint32),
+		authLock:        new(sync.Mutex),
+		isAuthenticated: new(atomic.Bool),
 	}
 }
 
@@ -40,8 +68,65 @@ func (con *Connection) ClientId() (clientId string) {
 	return con.clientId
 }
 
-// Break is a method used by a Client to finalise its connection.
+// Features returns the features negotiated for the connection.
+func (con *Connection) Features() (features hello.Feature) {
+	return hello.Feature(con.features.Load())
+}
+
+// SetFeatures sets the features negotiated for the connection.
+func (con *Connection) SetFeatures(features hello.Feature) {
+	con.features.Store(uint32(features))
+}
+
+// SetResponseSizeMax sets the maximum size of a response accepted by the other
+// side. Larger responses are not sent.
+func (con *Connection) SetResponseSizeMax(responseSizeMax uint32) {
+	con.responseSizeMax.Store(responseSizeMax)
+}
+
+// SetChallenge remembers the challenge sent to the other side.
+func (con *Connection) SetChallenge(challenge []byte) {
+	con.authLock.Lock()
+	defer con.authLock.Unlock()
+
+	con.challenge = challenge
+}
+
+// TakeChallenge returns the last challenge and forgets it, so that a
+// challenge can not be answered twice. Nil is returned when there is no
+// challenge.
+func (con *Connection) TakeChallenge() (challenge []byte) {
+	con.authLock.Lock()
+	defer con.authLock.Unlock()
+
+	challenge = con.challenge
+	con.challenge = nil
+	return challenge
+}
+
+// IsAuthenticated tells whether the other side has authenticated itself.
+func (con *Connection) IsAuthenticated() (isAuthenticated bool) {
+	return con.isAuthenticated.Load()
+}
+
+// SetAuthenticated marks the other side as authenticated.
+func (con *Connection) SetAuthenticated() {
+	con.isAuthenticated.Store(true)
+}
+
+// IsBroken tells whether the connection has been broken, i.e. closed.
+func (con *Connection) IsBroken() (isBroken bool) {
+	return con.isBroken.Load()
+}
+
+// Break is a method used by a Client to finalise its connection. Server also
+// uses it to finalise a client's connection. Breaking an already broken
+// connection does nothing.
 func (con *Connection) Break() (cerr *ce.CommonError) {
+	if con.isBroken.Swap(true) {
+		return nil
+	}
+
 	err := con.netConn.Close()
 	if err != nil {
 		return ce.NewServerError(err.Error(), 0, 0, con.clientId)
@@ -50,66 +135,37 @@ func (con *Connection) Break() (cerr *ce.CommonError) {
 	return nil
 }
 
-// SendRequestMessage is a method used by a Client to send a request to the
-// server.
-func (con *Connection) SendRequestMessage(req *request.Request) (cerr *ce.CommonError) {
-	var buf bytes.Buffer
-	var err error
-	var ba []byte
-
-	// 1. Size.
-	{
-		if req.Size == 0 {
-			rs := protocol.MethodNameLen + req.UID.Length()
-			if rs > math.MaxUint16 {
-				return ce.NewClientError(fmt.Sprintf(request.ErrSizeIsTooLong, rs), req.Method, 0, con.clientId)
-			}
-			req.Size = uint16(rs)
-		}
-
-		ba = make([]byte, protocol.RequestSizeLen)
-		switch protocol.Endianness {
-		case endianness.Endianness_BigEndian:
-			binary.BigEndian.PutUint16(ba, req.Size)
-
-		case endianness.Endianness_LittleEndian:
-			binary.LittleEndian.PutUint16(ba, req.Size)
-
-		default:
-			return ce.NewClientError(endianness.ErrEndiannessIsUnknown, req.Method, 0, con.clientId)
-		}
-
-		_, err = buf.Write(ba)
-		if err != nil {
-			return ce.NewClientError(err.Error(), req.Method, 0, con.clientId)
-		}
+// InterruptReading makes the current and all the next reads of the
+// connection fail, while the connection is still able to send messages. It
+// is used by server to stop reading requests of a client without losing
+// responses which are being sent.
+func (con *Connection) InterruptReading() (cerr *ce.CommonError) {
+	err := con.netConn.SetReadDeadline(time.Now())
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.clientId)
 	}
 
-	// 2. Method.
-	{
-		ba, err = req.Method.Bytes()
-		if err != nil {
-			return ce.NewClientError(err.Error(), req.Method, 0, con.clientId)
-		}
+	return nil
+}
 
-		_, err = buf.Write(ba)
-		if err != nil {
-			return ce.NewClientError(err.Error(), req.Method, 0, con.clientId)
-		}
-	}
+// SendRequestMessage is a method used by a Client to send a request to the
+// server.
+func (con *Connection) SendRequestMessage(req *request.Request) (cerr *ce.CommonError) {
+	return con.SendRequestMessageWithDeadline(req, time.Time{})
+}
 
-	// 3. UID.
-	{
-		if req.UID != nil {
-			_, err = buf.Write(req.UID.Bytes())
-			if err != nil {
-				return ce.NewClientError(err.Error(), req.Method, 0, con.clientId)
-			}
-		}
+// SendRequestMessageWithDeadline is a method used by a Client to send a
+// request to the server. Writing fails when it is not finished before the
+// deadline. Zero deadline means no limit.
+func (con *Connection) SendRequestMessageWithDeadline(req *request.Request, deadline time.Time) (cerr *ce.CommonError) {
+	var buf bytes.Buffer
+	err := codec.EncodeRequest(&buf, req)
+	if err != nil {
+		return ce.NewClientError(err.Error(), req.Method, 0, con.clientId)
 	}
 
 	// Send data.
-	_, err = con.netConn.Write(buf.Bytes())
+	err = con.sendWithDeadline(buf.Bytes(), deadline)
 	if err != nil {
 		return ce.NewClientError(err.Error(), req.Method, 0, con.clientId)
 	}
@@ -120,117 +176,91 @@ func (con *Connection) SendRequestMessage(req *request.Request) (cerr *ce.Common
 // GetResponseMessage is a method used by a Client to read a response from the
 // server.
 func (con *Connection) GetResponseMessage() (resp *response.Response, cerr *ce.CommonError) {
-	resp = &response.Response{}
-	var err error
-	var ba []byte
-
-	// 1. Size.
-	{
-		ba, err = tcp.ReadExactSize(con.netConn, protocol.ResponseSizeLen)
-		if err != nil {
-			return nil, ce.NewClientError(err.Error(), 0, 0, con.clientId)
-		}
-
-		switch protocol.Endianness {
-		case endianness.Endianness_BigEndian:
-			resp.Size = binary.BigEndian.Uint32(ba)
-
-		case endianness.Endianness_LittleEndian:
-			resp.Size = binary.LittleEndian.Uint32(ba)
-
-		default:
-			return nil, ce.NewClientError(endianness.ErrEndiannessIsUnknown, 0, 0, con.clientId)
-		}
-
-		if resp.Size < protocol.StatusNameLen {
-			return nil, ce.NewClientError(fmt.Sprintf(response.ErrSizeIsTooShort, resp.Size), 0, 0, con.clientId)
-		}
+	resp, cerr = con.GetResponseHeader()
+	if cerr != nil {
+		return nil, cerr
 	}
 
-	// 2. Status.
-	{
-		ba, err = tcp.ReadExactSize(con.netConn, protocol.StatusNameLen)
-		if err != nil {
-			return nil, ce.NewClientError(err.Error(), 0, 0, con.clientId)
-		}
-
-		resp.Status, err = status.NewFromString(string(ba[0:protocol.StatusNameLen]))
-		if err != nil {
-			return nil, ce.NewClientError(err.Error(), 0, 0, con.clientId)
-		}
-	}
-
-	// 3. Data.
-	{
-		dataSize := uint(resp.Size) - uint(protocol.StatusNameLen)
-		if dataSize > 0 {
-			resp.Data, err = tcp.ReadExactSize(con.netConn, dataSize)
-			if err != nil {
-				return nil, ce.NewClientError(err.Error(), 0, resp.Status, con.clientId)
-			}
-		}
+	cerr = con.GetResponseData(resp)
+	if cerr != nil {
+		return nil, cerr
 	}
 
 	return resp, nil
 }
 
-// GetNextRequest is a method used by a Server to receive a request from the
-// client.
-func (con *Connection) GetNextRequest() (req *request.Request, cerr *ce.CommonError) {
-	req = &request.Request{}
+// GetResponseHeader is a method used by a Client to read a response from the
+// server without its data. Data must be read after the header using either
+// the 'GetResponseData' method or the 'GetResponseDataReader' method.
+func (con *Connection) GetResponseHeader() (resp *response.Response, cerr *ce.CommonError) {
 	var err error
-	var ba []byte
+	resp, err = codec.DecodeResponseHeader(con.netConn)
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, con.clientId)
+	}
 
-	// 1. Size.
-	{
-		ba, err = tcp.ReadExactSize(con.netConn, protocol.RequestSizeLen)
-		if err != nil {
-			return nil, ce.NewServerError(err.Error(), 0, 0, con.clientId)
-		}
+	return resp, nil
+}
 
-		switch protocol.Endianness {
-		case endianness.Endianness_BigEndian:
-			req.Size = binary.BigEndian.Uint16(ba)
+// GetResponseData is a method used by a Client to read data of a response
+// after its header.
+func (con *Connection) GetResponseData(resp *response.Response) (cerr *ce.CommonError) {
+	err := codec.DecodeResponseData(con.netConn, resp)
+	if err != nil {
+		return ce.NewClientError(err.Error(), 0, resp.Status, con.clientId)
+	}
 
-		case endianness.Endianness_LittleEndian:
-			req.Size = binary.LittleEndian.Uint16(ba)
+	return nil
+}
 
-		default:
-			return nil, ce.NewServerError(endianness.ErrEndiannessIsUnknown, 0, 0, con.clientId)
-		}
+// GetResponseDataReader is a method used by a Client to get a reader of data
+// of a response after its header. Data must be read completely before the
+// next response is read. When the connection ends before all the data is
+// read, the reader fails with 'io.ErrUnexpectedEOF'.
+func (con *Connection) GetResponseDataReader(resp *response.Response) (rdr io.Reader) {
+	return &dataReader{rdr: con.netConn, left: int64(resp.DataSize())}
+}
 
-		if req.Size < protocol.MethodNameLen {
-			return nil, ce.NewServerError(fmt.Sprintf(request.ErrSizeIsTooShort, req.Size), 0, 0, con.clientId)
-		}
+// dataReader reads a known amount of data. Unlike 'io.LimitReader', it does
+// not treat the end of the underlying stream as the end of data.
+type dataReader struct {
+	rdr  io.Reader
+	left int64
+}
+
+// Read is the standard method of the 'io.Reader' interface.
+func (dr *dataReader) Read(dst []byte) (n int, err error) {
+	if dr.left <= 0 {
+		return 0, io.EOF
 	}
 
-	// 2. Method.
-	{
-		ba, err = tcp.ReadExactSize(con.netConn, protocol.MethodNameLen)
-		if err != nil {
-			return nil, ce.NewServerError(err.Error(), 0, 0, con.clientId)
-		}
+	if int64(len(dst)) > dr.left {
+		dst = dst[:dr.left]
+	}
 
-		req.Method, err = method.NewFromString(string(ba[0:protocol.MethodNameLen]))
-		if err != nil {
-			return nil, ce.NewServerError(err.Error(), 0, 0, con.clientId)
-		}
+	n, err = dr.rdr.Read(dst)
+	dr.left -= int64(n)
+	if (err == io.EOF) && (dr.left > 0) {
+		err = io.ErrUnexpectedEOF
 	}
 
-	// 3. UID.
-	{
-		uidSize := uint(req.Size) - uint(protocol.MethodNameLen)
-		if uidSize > 0 {
-			ba, err = tcp.ReadExactSize(con.netConn, uidSize)
-			if err != nil {
-				return nil, ce.NewServerError(err.Error(), req.Method, 0, con.clientId)
-			}
-		}
+	return n, err
+}
 
-		req.UID, err = uid.New(string(ba[0:uidSize]))
-		if err != nil {
-			return nil, ce.NewServerError(err.Error(), req.Method, 0, con.clientId)
+// GetNextRequest is a method used by a Server to receive a request from the
+// client. When the request is received completely, but it is not valid, the
+// request is returned together with a client's error, so that the error could
+// be reported to the client.
+func (con *Connection) GetNextRequest() (req *request.Request, cerr *ce.CommonError) {
+	var err error
+	req, err = codec.DecodeRequest(con.netConn)
+	if err != nil {
+		var requestErr *codec.RequestError
+		if errors.As(err, &requestErr) {
+			return req, ce.NewClientErrorWithCode(requestErr.Code, requestErr.Msg, req.Method, 0, con.clientId)
 		}
+
+		return nil, ce.NewServerError(err.Error(), 0, 0, con.clientId)
 	}
 
 	return req, nil
@@ -239,64 +269,98 @@ func (con *Connection) GetNextRequest() (req *request.Request, cerr *ce.CommonEr
 // SendResponseMessage is a method used by a Server to send a response to the
 // client.
 func (con *Connection) SendResponseMessage(resp *response.Response) (cerr *ce.CommonError) {
-	var buf bytes.Buffer
-	var ba []byte
-	var err error
+	var buf *bytes.Buffer
+	buf, cerr = con.encodeResponseHeader(resp, len(resp.Data))
+	if cerr != nil {
+		return cerr
+	}
 
-	// 1. Size.
-	{
-		if resp.Size == 0 {
-			rs := protocol.StatusNameLen + len(resp.Data)
-			if rs > math.MaxUint32 {
-				return ce.NewServerError(fmt.Sprintf(response.ErrSizeIsTooLong, rs), 0, resp.Status, con.clientId)
-			}
-			resp.Size = uint32(rs)
-		}
+	_, err := buf.Write(resp.Data)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
+	}
 
-		ba = make([]byte, protocol.ResponseSizeLen)
-		switch protocol.Endianness {
-		case endianness.Endianness_BigEndian:
-			binary.BigEndian.PutUint32(ba, resp.Size)
+	// Send data.
+	err = con.send(buf.Bytes())
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
+	}
+
+	return nil
+}
 
-		case endianness.Endianness_LittleEndian:
-			binary.LittleEndian.PutUint32(ba, resp.Size)
+// SendResponseStream is a method used by a Server to send a response to the
+// client when the data of the response is too large to be stored in memory.
+// Data is copied from the reader directly into the network connection.
+func (con *Connection) SendResponseStream(resp *response.Response, data io.Reader, dataSize int) (cerr *ce.CommonError) {
+	var buf *bytes.Buffer
+	buf, cerr = con.encodeResponseHeader(resp, dataSize)
+	if cerr != nil {
+		return cerr
+	}
 
-		default:
-			return ce.NewServerError(endianness.ErrEndiannessIsUnknown, 0, resp.Status, con.clientId)
-		}
+	con.sendLock.Lock()
+	defer con.sendLock.Unlock()
 
-		_, err = buf.Write(ba)
-		if err != nil {
-			return ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
-		}
+	_, err := con.netConn.Write(buf.Bytes())
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
 	}
 
-	// 2. Status.
-	{
-		ba, err = resp.Status.Bytes()
-		if err != nil {
-			return ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
-		}
+	var n int64
+	n, err = io.Copy(con.netConn, io.LimitReader(data, int64(dataSize)))
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
+	}
+	if n != int64(dataSize) {
+		// The message is incomplete, the connection can not be used any more.
+		return ce.NewServerError(fmt.Sprintf(ErrDataSizeMismatch, n, dataSize), 0, resp.Status, con.clientId)
+	}
 
-		_, err = buf.Write(ba)
-		if err != nil {
-			return ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
-		}
+	return nil
+}
+
+// encodeResponseHeader encodes all the parts of a response except its data.
+func (con *Connection) encodeResponseHeader(resp *response.Response, dataSize int) (buf *bytes.Buffer, cerr *ce.CommonError) {
+	buf = new(bytes.Buffer)
+	err := codec.EncodeResponseHeader(buf, resp, dataSize)
+	if err != nil {
+		return nil, ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
 	}
 
-	// 3. Data.
-	{
-		_, err = buf.Write(resp.Data)
+	responseSizeMax := con.responseSizeMax.Load()
+	if (responseSizeMax > 0) && (resp.Size > responseSizeMax) {
+		return nil, ce.NewClientErrorWithCode(ec.ErrorCode_ItemIsTooLarge, fmt.Sprintf(ErrResponseIsTooLarge, resp.Size, responseSizeMax), 0, resp.Status, con.clientId)
+	}
+
+	return buf, nil
+}
+
+// send writes the message into the network connection as a single piece.
+func (con *Connection) send(message []byte) (err error) {
+	return con.sendWithDeadline(message, time.Time{})
+}
+
+// sendWithDeadline writes the message into the network connection as a single
+// piece. Zero deadline means no limit.
+func (con *Connection) sendWithDeadline(message []byte, deadline time.Time) (err error) {
+	con.sendLock.Lock()
+	defer con.sendLock.Unlock()
+
+	if !deadline.IsZero() {
+		err = con.netConn.SetWriteDeadline(deadline)
 		if err != nil {
-			return ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
+			return err
 		}
-	}
 
-	// Send data.
-	_, err = con.netConn.Write(buf.Bytes())
-	if err != nil {
-		return ce.NewServerError(err.Error(), 0, resp.Status, con.clientId)
+		defer func() {
+			derr := con.netConn.SetWriteDeadline(time.Time{})
+			if derr != nil {
+				err = ae.Combine(err, derr)
+			}
+		}()
 	}
 
-	return nil
+	_, err = con.netConn.Write(message)
+	return err
 }
@@ -2,8 +2,11 @@ package ds
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"strings"
 
+	as "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ArchiveStorage"
 	"github.com/vault-thirteen/auxie/number"
 )
 
@@ -14,11 +17,18 @@ const (
 	ErrCacheVolumeMaxIsNotSet      = "cache's maximum volume is not set"
 	ErrCachedItemVolumeMaxIsNotSet = "cached item's maximum volume is not set"
 	ErrCachedItemTTLIsNotSet       = "cached item's TTL is not set"
+	ErrDataFolderIsNotFound        = "data folder is not found: %s"
+	ErrArchiveIsNotFound           = "archive is not found: %s"
 )
 
 type DataSettings struct {
 	// 1. Folder with data files.
-	Folder string
+	// Data files may be stored in a ZIP or a TAR archive instead of a folder.
+	// Such an archive is set on the same line of the settings file, it is
+	// recognised by its extension. A folder which name has the extension of
+	// an archive is still a folder.
+	Folder  string
+	Archive string
 
 	// 2. File extension.
 	// This extension is concatenated with UIDs of cached items to get the full
@@ -35,15 +45,39 @@ type DataSettings struct {
 	// 1 Hour = 3600 Seconds,
 	// 1 Day = 86400 Seconds.
 	CachedItemTTL uint
+
+	// 6. Maximum size of cache of compressed copies of items in bytes.
+	// It is optional. Compressed copies are kept in a separate cache, they
+	// are not kept when it is zero.
+	CompressedCacheVolumeMax int
 }
 
 func ParseDataSettings(line1, line2 string) (ds *DataSettings, err error) {
-	ds = &DataSettings{
-		Folder: strings.TrimSpace(line1),
+	ds = &DataSettings{}
+
+	line1 = strings.TrimSpace(line1)
+	isArchive := as.FormatOfPath(line1) != as.Format_Unknown
+
+	var fi os.FileInfo
+	fi, err = os.Stat(line1)
+	if errors.Is(err, os.ErrNotExist) {
+		if isArchive {
+			return nil, fmt.Errorf(ErrArchiveIsNotFound, line1)
+		}
+		return nil, fmt.Errorf(ErrDataFolderIsNotFound, line1)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if isArchive && !fi.IsDir() {
+		ds.Archive = line1
+	} else {
+		ds.Folder = line1
 	}
 
 	parts := strings.Split(strings.TrimSpace(line2), " ")
-	if len(parts) != 4 {
+	if (len(parts) != 4) && (len(parts) != 5) {
 		return nil, errors.New(ErrSyntax)
 	}
 
@@ -70,14 +104,27 @@ func ParseDataSettings(line1, line2 string) (ds *DataSettings, err error) {
 		return nil, err
 	}
 
+	if len(parts) == 5 {
+		ds.CompressedCacheVolumeMax, err = number.ParseInt(parts[4])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return ds, nil
 }
 
 func (ds *DataSettings) Check() (err error) {
-	if len(ds.Folder) == 0 {
+	if (len(ds.Folder) == 0) && (len(ds.Archive) == 0) {
 		return errors.New(ErrDataFolderIsNotSet)
 	}
 
+	return ds.CheckWithoutFolder()
+}
+
+// CheckWithoutFolder checks all the settings except the folder and the
+// archive. It is used when data files are stored in another storage.
+func (ds *DataSettings) CheckWithoutFolder() (err error) {
 	if len(ds.FileExtension) == 0 {
 		return errors.New(ErrDataFileExtensionIsNotSet)
 	}
@@ -0,0 +1,64 @@
+package ds
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_ParseDataSettings_FolderOrArchive(t *testing.T) {
+	dir := t.TempDir()
+
+	folder := filepath.Join(dir, "data")
+	err := os.Mkdir(folder, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A folder which name looks like a name of an archive.
+	zipFolder := filepath.Join(dir, "data.zip")
+	err = os.Mkdir(zipFolder, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(dir, "data.tar")
+	err = os.WriteFile(archive, nil, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		line1   string
+		folder  string
+		archive string
+	}{
+		{line1: folder, folder: folder},
+		{line1: zipFolder, folder: zipFolder},
+		{line1: archive, archive: archive},
+	} {
+		ds, err := ParseDataSettings(tc.line1, "txt 1000 100 60")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if (ds.Folder != tc.folder) || (ds.Archive != tc.archive) {
+			t.Fatalf("%s: folder %q, archive %q", tc.line1, ds.Folder, ds.Archive)
+		}
+	}
+}
+
+func Test_ParseDataSettings_NotFound(t *testing.T) {
+	dir := t.TempDir()
+
+	for line1, expected := range map[string]string{
+		filepath.Join(dir, "missing.zip"): "archive is not found",
+		filepath.Join(dir, "missing"):     "data folder is not found",
+	} {
+		_, err := ParseDataSettings(line1, "txt 1000 100 60")
+		if (err == nil) || !strings.HasPrefix(err.Error(), expected) {
+			t.Fatalf("%s: unexpected error: %v", line1, err)
+		}
+	}
+}
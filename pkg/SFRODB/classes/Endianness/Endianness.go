@@ -1,5 +1,10 @@
 package endianness
 
+import (
+	"encoding/binary"
+	"errors"
+)
+
 const (
 	Endianness_BigEndian    = Endianness(1)
 	Endianness_LittleEndian = Endianness(2)
@@ -10,3 +15,23 @@ const (
 )
 
 type Endianness byte
+
+// ByteOrder is a byte order which is able both to put and to append numbers.
+type ByteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// ByteOrder returns the byte order of the endianness.
+func (e Endianness) ByteOrder() (bo ByteOrder, err error) {
+	switch e {
+	case Endianness_BigEndian:
+		return binary.BigEndian, nil
+
+	case Endianness_LittleEndian:
+		return binary.LittleEndian, nil
+
+	default:
+		return nil, errors.New(ErrEndiannessIsUnknown)
+	}
+}
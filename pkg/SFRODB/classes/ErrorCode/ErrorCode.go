@@ -0,0 +1,88 @@
+package ec
+
+import (
+	"strconv"
+)
+
+// ErrorCode is a machine-readable code of a client's error which is sent by
+// server together with the 'Status_ClientError' status.
+type ErrorCode uint16
+
+const (
+	// ErrorCode_None means that an error has no code, e.g. when the error was
+	// not reported by server.
+	ErrorCode_None = ErrorCode(0)
+
+	// ErrorCode_Unknown is an error without details. Old servers send the
+	// client error status without any code.
+	ErrorCode_Unknown = ErrorCode(1)
+
+	// ErrorCode_MethodIsNotSupported is an unknown or unsupported method.
+	ErrorCode_MethodIsNotSupported = ErrorCode(2)
+
+	// ErrorCode_UidIsNotValid is an invalid UID, see 'uid.ErrNotValid'.
+	ErrorCode_UidIsNotValid = ErrorCode(3)
+
+	// ErrorCode_FileDoesNotExist is a missing record, see
+	// 'storage.ErrFileDoesNotExist'.
+	ErrorCode_FileDoesNotExist = ErrorCode(4)
+
+	// ErrorCode_PathIsNotValid is a UID which leads outside the data folder,
+	// see 'storage.ErrRelPathIsNotValid'.
+	ErrorCode_PathIsNotValid = ErrorCode(5)
+
+	// ErrorCode_ParametersAreNotValid are invalid parameters of a request.
+	ErrorCode_ParametersAreNotValid = ErrorCode(6)
+
+	// ErrorCode_RangeIsNotValid is a part of a record which is outside the
+	// record.
+	ErrorCode_RangeIsNotValid = ErrorCode(7)
+
+	// ErrorCode_ItemIsTooLarge is a response which exceeds the maximum size
+	// of a response announced by the client.
+	ErrorCode_ItemIsTooLarge = ErrorCode(8)
+
+	// ErrorCode_AuthenticationIsRequired is a request which is not allowed
+	// before the client authenticates itself.
+	ErrorCode_AuthenticationIsRequired = ErrorCode(9)
+
+	// ErrorCode_AuthenticationFailed is a wrong answer to a challenge, or an
+	// answer without a challenge.
+	ErrorCode_AuthenticationFailed = ErrorCode(10)
+
+	// ErrorCode_ServerError is an error of server which is reported as a
+	// part of a response, e.g. for a single item of a batch. Details of the
+	// error are not sent to the client.
+	ErrorCode_ServerError = ErrorCode(11)
+)
+
+func (c ErrorCode) String() string {
+	switch c {
+	case ErrorCode_None:
+		return "None"
+	case ErrorCode_Unknown:
+		return "Unknown"
+	case ErrorCode_MethodIsNotSupported:
+		return "MethodIsNotSupported"
+	case ErrorCode_UidIsNotValid:
+		return "UidIsNotValid"
+	case ErrorCode_FileDoesNotExist:
+		return "FileDoesNotExist"
+	case ErrorCode_PathIsNotValid:
+		return "PathIsNotValid"
+	case ErrorCode_ParametersAreNotValid:
+		return "ParametersAreNotValid"
+	case ErrorCode_RangeIsNotValid:
+		return "RangeIsNotValid"
+	case ErrorCode_ItemIsTooLarge:
+		return "ItemIsTooLarge"
+	case ErrorCode_AuthenticationIsRequired:
+		return "AuthenticationIsRequired"
+	case ErrorCode_AuthenticationFailed:
+		return "AuthenticationFailed"
+	case ErrorCode_ServerError:
+		return "ServerError"
+	default:
+		return strconv.Itoa(int(c))
+	}
+}
@@ -0,0 +1,84 @@
+package event
+
+import (
+	"fmt"
+)
+
+const (
+	ErrUnknownType = "unknown event type: %v"
+)
+
+// Type is a type of event.
+type Type byte
+
+const (
+	Type_Unknown = Type(0)
+
+	// Type_RecordChanged means that the file of a record has been created,
+	// modified or removed.
+	Type_RecordChanged = Type(1)
+
+	// Type_RecordForgotten means that a record has been removed from cache by
+	// a client's request.
+	Type_RecordForgotten = Type(2)
+
+	// Type_CacheReset means that the whole cache has been reset. Events of
+	// this type have no UID.
+	Type_CacheReset = Type(3)
+)
+
+func NewType(b byte) (t Type, err error) {
+	t = Type(b)
+
+	switch t {
+	case Type_RecordChanged,
+		Type_RecordForgotten,
+		Type_CacheReset:
+		return t, nil
+
+	default:
+		return Type_Unknown, fmt.Errorf(ErrUnknownType, b)
+	}
+}
+
+func (t Type) String() string {
+	switch t {
+	case Type_RecordChanged:
+		return "RecordChanged"
+	case Type_RecordForgotten:
+		return "RecordForgotten"
+	case Type_CacheReset:
+		return "CacheReset"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is a notification about a change which is sent by server to clients
+// watching the changes.
+type Event struct {
+	Type Type
+
+	// UID of the record, if the event is related to a single record.
+	UID string
+}
+
+func New_RecordChanged(uid string) (e *Event) {
+	return &Event{Type: Type_RecordChanged, UID: uid}
+}
+
+func New_RecordForgotten(uid string) (e *Event) {
+	return &Event{Type: Type_RecordForgotten, UID: uid}
+}
+
+func New_CacheReset() (e *Event) {
+	return &Event{Type: Type_CacheReset}
+}
+
+func (e *Event) String() string {
+	if len(e.UID) == 0 {
+		return e.Type.String()
+	}
+
+	return e.Type.String() + " " + e.UID
+}
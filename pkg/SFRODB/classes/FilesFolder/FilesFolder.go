@@ -3,22 +3,24 @@ package ff
 import (
 	"errors"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
 	ae "github.com/vault-thirteen/auxie/errors"
 	"github.com/vault-thirteen/auxie/file"
 )
 
 const (
-	ErrFolderIsNotFound  = "folder is not found: %s"
-	ErrFileDoesNotExist  = "file does not exist: %s"
-	ErrRelPathIsNotValid = "relative path is not valid"
+	ErrFolderIsNotFound = "folder is not found: %s"
 )
 
+// FilesFolder is a storage of data files in a folder of the local disk, see
+// the 'storage.Storage' interface.
 type FilesFolder struct {
 	folder        string
 	storageAccess *sync.Mutex
@@ -43,48 +45,48 @@ func New(baseFolder string) (ff *FilesFolder, err error) {
 	return ff, nil
 }
 
-func (ff *FilesFolder) GetFileContents(relPath string) (fileExists bool, data []byte, err error) {
-	if !isRelPathValid(relPath) {
-		return false, nil, errors.New(ErrRelPathIsNotValid)
+// OpenFile opens a file for reading and returns its size. The caller must
+// close the file.
+func (ff *FilesFolder) OpenFile(relPath string) (fileExists bool, f storage.File, fileSize int64, err error) {
+	if !storage.IsRelPathValid(relPath) {
+		return false, nil, 0, errors.New(storage.ErrRelPathIsNotValid)
 	}
 
-	filePath := filepath.Join(ff.folder, relPath)
+	filePath := filepath.Join(ff.folder, filepath.FromSlash(relPath))
 
 	ff.storageAccess.Lock()
 	defer ff.storageAccess.Unlock()
 
 	fileExists, err = file.FileExists(filePath)
 	if !fileExists {
-		return false, nil, fmt.Errorf(ErrFileDoesNotExist, filePath)
+		return false, nil, 0, fmt.Errorf(storage.ErrFileDoesNotExist, filePath)
 	}
 
-	var f *os.File
-	f, err = os.Open(filePath)
+	var osFile *os.File
+	osFile, err = os.Open(filePath)
 	if err != nil {
-		return fileExists, nil, err
+		return fileExists, nil, 0, err
 	}
 
-	defer func() {
-		derr := f.Close()
+	var fi os.FileInfo
+	fi, err = osFile.Stat()
+	if err != nil {
+		derr := osFile.Close()
 		if derr != nil {
 			err = ae.Combine(err, derr)
 		}
-	}()
-
-	data, err = io.ReadAll(f)
-	if err != nil {
-		return fileExists, nil, err
+		return fileExists, nil, 0, err
 	}
 
-	return true, data, nil
+	return true, osFile, fi.Size(), nil
 }
 
 func (ff *FilesFolder) FileExists(relPath string) (fileExists bool, err error) {
-	if !isRelPathValid(relPath) {
-		return false, errors.New(ErrRelPathIsNotValid)
+	if !storage.IsRelPathValid(relPath) {
+		return false, errors.New(storage.ErrRelPathIsNotValid)
 	}
 
-	filePath := filepath.Join(ff.folder, relPath)
+	filePath := filepath.Join(ff.folder, filepath.FromSlash(relPath))
 
 	ff.storageAccess.Lock()
 	defer ff.storageAccess.Unlock()
@@ -92,6 +94,95 @@ func (ff *FilesFolder) FileExists(relPath string) (fileExists bool, err error) {
 	return file.FileExists(filePath)
 }
 
-func isRelPathValid(relPath string) (ok bool) {
-	return !strings.Contains(relPath, "..")
+// StatFile returns the state of a file without reading it.
+func (ff *FilesFolder) StatFile(relPath string) (fileExists bool, state storage.FileState, err error) {
+	if !storage.IsRelPathValid(relPath) {
+		return false, state, errors.New(storage.ErrRelPathIsNotValid)
+	}
+
+	filePath := filepath.Join(ff.folder, filepath.FromSlash(relPath))
+
+	ff.storageAccess.Lock()
+	defer ff.storageAccess.Unlock()
+
+	var fi os.FileInfo
+	fi, err = os.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, state, fmt.Errorf(storage.ErrFileDoesNotExist, filePath)
+		}
+		return false, state, err
+	}
+	if fi.IsDir() {
+		return false, state, fmt.Errorf(storage.ErrFileDoesNotExist, filePath)
+	}
+
+	return true, storage.FileState{Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+// ListFiles returns relative paths of all the files having the extension in
+// the folder and its sub-folders. Paths use forward slashes as separators and
+// are sorted.
+func (ff *FilesFolder) ListFiles(extension string) (relPaths []string, err error) {
+	relPaths = make([]string, 0)
+	err = ff.walkFiles(extension, func(relPath string, d fs.DirEntry) error {
+		relPaths = append(relPaths, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(relPaths)
+
+	return relPaths, nil
+}
+
+// ScanFiles returns states of all the files having the extension in the
+// folder and its sub-folders by their relative paths, see the 'ListFiles'
+// method. States of two scans may be compared to find changed files.
+func (ff *FilesFolder) ScanFiles(extension string) (states map[string]storage.FileState, err error) {
+	states = make(map[string]storage.FileState)
+	err = ff.walkFiles(extension, func(relPath string, d fs.DirEntry) error {
+		fi, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// The file has been removed during the scan.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		states[relPath] = storage.FileState{Size: fi.Size(), ModTime: fi.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+// walkFiles calls the function for each regular file having the extension in
+// the folder and its sub-folders.
+func (ff *FilesFolder) walkFiles(extension string, fn func(relPath string, d fs.DirEntry) error) (err error) {
+	ff.storageAccess.Lock()
+	defer ff.storageAccess.Unlock()
+
+	return filepath.WalkDir(ff.folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !strings.HasSuffix(d.Name(), extension) {
+			return nil
+		}
+
+		var relPath string
+		relPath, err = filepath.Rel(ff.folder, path)
+		if err != nil {
+			return err
+		}
+
+		return fn(filepath.ToSlash(relPath), d)
+	})
 }
@@ -0,0 +1,162 @@
+package fss
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+	ae "github.com/vault-thirteen/auxie/errors"
+)
+
+const (
+	ErrFileSystemIsNotSet = "file system is not set"
+)
+
+// FsStorage is a storage of data files in a file system of the 'io/fs'
+// package, see the 'storage.Storage' interface. It may serve files compiled
+// into the program with the 'embed' package. The file system must be safe
+// for concurrent use, which is true for the standard file systems.
+type FsStorage struct {
+	fsys fs.FS
+}
+
+func New(fsys fs.FS) (fss *FsStorage, err error) {
+	if fsys == nil {
+		return nil, errors.New(ErrFileSystemIsNotSet)
+	}
+
+	return &FsStorage{fsys: fsys}, nil
+}
+
+// OpenFile opens a file for reading and returns its size. The caller must
+// close the file. Files which do not support reading at an offset are read
+// into memory.
+func (fss *FsStorage) OpenFile(relPath string) (fileExists bool, f storage.File, fileSize int64, err error) {
+	var fi fs.FileInfo
+	fileExists, fi, err = fss.stat(relPath)
+	if err != nil {
+		return false, nil, 0, err
+	}
+	if !fileExists {
+		return false, nil, 0, fmt.Errorf(storage.ErrFileDoesNotExist, relPath)
+	}
+
+	var fsFile fs.File
+	fsFile, err = fss.fsys.Open(relPath)
+	if err != nil {
+		return fileExists, nil, 0, err
+	}
+
+	sf, ok := fsFile.(storage.File)
+	if ok {
+		return true, sf, fi.Size(), nil
+	}
+
+	var data []byte
+	data, err = io.ReadAll(fsFile)
+	err = ae.Combine(err, fsFile.Close())
+	if err != nil {
+		return fileExists, nil, 0, err
+	}
+
+	return true, storage.NewBytesFile(data), int64(len(data)), nil
+}
+
+func (fss *FsStorage) FileExists(relPath string) (fileExists bool, err error) {
+	fileExists, _, err = fss.stat(relPath)
+	return fileExists, err
+}
+
+// StatFile returns the state of a file without reading it.
+func (fss *FsStorage) StatFile(relPath string) (fileExists bool, state storage.FileState, err error) {
+	var fi fs.FileInfo
+	fileExists, fi, err = fss.stat(relPath)
+	if err != nil {
+		return false, state, err
+	}
+	if !fileExists {
+		return false, state, fmt.Errorf(storage.ErrFileDoesNotExist, relPath)
+	}
+
+	return true, storage.FileState{Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+// stat returns information about a file. Folders are not files. A missing
+// file is not an error.
+func (fss *FsStorage) stat(relPath string) (fileExists bool, fi fs.FileInfo, err error) {
+	if !storage.IsRelPathValid(relPath) || !fs.ValidPath(relPath) {
+		return false, nil, errors.New(storage.ErrRelPathIsNotValid)
+	}
+
+	fi, err = fs.Stat(fss.fsys, relPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	if fi.IsDir() {
+		return false, nil, nil
+	}
+
+	return true, fi, nil
+}
+
+// ListFiles returns relative paths of all the files having the extension.
+// Paths are sorted.
+func (fss *FsStorage) ListFiles(extension string) (relPaths []string, err error) {
+	relPaths = make([]string, 0)
+	err = fss.walkFiles(extension, func(relPath string, d fs.DirEntry) error {
+		relPaths = append(relPaths, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(relPaths)
+
+	return relPaths, nil
+}
+
+// ScanFiles returns states of all the files having the extension by their
+// relative paths.
+func (fss *FsStorage) ScanFiles(extension string) (states map[string]storage.FileState, err error) {
+	states = make(map[string]storage.FileState)
+	err = fss.walkFiles(extension, func(relPath string, d fs.DirEntry) error {
+		fi, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// The file has been removed during the scan.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		states[relPath] = storage.FileState{Size: fi.Size(), ModTime: fi.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+// walkFiles calls the function for each regular file having the extension.
+func (fss *FsStorage) walkFiles(extension string, fn func(relPath string, d fs.DirEntry) error) (err error) {
+	return fs.WalkDir(fss.fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !strings.HasSuffix(d.Name(), extension) {
+			return nil
+		}
+
+		return fn(path, d)
+	})
+}
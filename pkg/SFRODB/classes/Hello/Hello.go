@@ -0,0 +1,221 @@
+package hello
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Endianness"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
+)
+
+const (
+	ErrHelloIsTooShort = "hello message is too short: %v"
+)
+
+// EncodedLen is the length of an encoded hello message. Newer versions of the
+// protocol may append more fields to the message, so longer messages are
+// accepted and the unknown tail is ignored.
+const EncodedLen = 2 + 2 + 4 + 2 + 2 + 2 + 4
+
+// Feature is a set of optional features of the protocol.
+type Feature uint32
+
+const (
+	// Feature_RequestIds means that requests have IDs and may be pipelined.
+	Feature_RequestIds = Feature(1 << iota)
+
+	// Feature_Streaming means that large records are streamed.
+	Feature_Streaming
+
+	// Feature_Ranges means that parts of records may be requested.
+	Feature_Ranges
+
+	// Feature_Batches means that many records may be requested at once.
+	Feature_Batches
+
+	// Feature_Stat means that metadata of records may be requested.
+	Feature_Stat
+
+	// Feature_ConditionalReads means that records may be requested only when
+	// they are modified.
+	Feature_ConditionalReads
+
+	// Feature_CompressionGzip means that data may be compressed with gzip.
+	Feature_CompressionGzip
+
+	// Feature_CompressionDeflate means that data may be compressed with
+	// deflate.
+	Feature_CompressionDeflate
+
+	// Feature_Ping means that connections may be checked with the ping
+	// method.
+	Feature_Ping
+
+	// Feature_Listing means that records may be listed.
+	Feature_Listing
+
+	// Feature_Watch means that changes may be watched.
+	Feature_Watch
+
+	// Feature_WarmUp means that records may be loaded into cache in advance.
+	Feature_WarmUp
+
+	// Feature_CacheStats means that statistics of cache may be requested.
+	Feature_CacheStats
+
+	// Feature_ServerInfo means that information about server may be
+	// requested.
+	Feature_ServerInfo
+
+	// Feature_Auth means that clients must authenticate themselves on the
+	// port before they use its methods. Server announces it only on a port
+	// which requires authentication.
+	Feature_Auth
+
+	// Feature_Tls means that the connection is secured with TLS. Server
+	// announces it only when TLS is enabled.
+	Feature_Tls
+)
+
+// Features_Compression are the features of compression.
+const Features_Compression = Feature_CompressionGzip | Feature_CompressionDeflate
+
+// Features_Connection are the features which describe the connection rather
+// than abilities of a side. Server announces them depending on its settings.
+const Features_Connection = Feature_Auth | Feature_Tls
+
+// featureNames are names of the features in the order of their bits.
+var featureNames = []string{
+	"RequestIds",
+	"Streaming",
+	"Ranges",
+	"Batches",
+	"Stat",
+	"ConditionalReads",
+	"CompressionGzip",
+	"CompressionDeflate",
+	"Ping",
+	"Listing",
+	"Watch",
+	"WarmUp",
+	"CacheStats",
+	"ServerInfo",
+	"Auth",
+	"Tls",
+}
+
+// Features_All are all the features supported by this implementation.
+const Features_All = Feature_RequestIds |
+	Feature_Streaming |
+	Feature_Ranges |
+	Feature_Batches |
+	Feature_Stat |
+	Feature_ConditionalReads |
+	Features_Compression |
+	Feature_Ping |
+	Feature_Listing |
+	Feature_Watch |
+	Feature_WarmUp |
+	Feature_CacheStats |
+	Feature_ServerInfo |
+	Features_Connection
+
+// Has checks whether all the specified features are in the set.
+func (f Feature) Has(features Feature) bool {
+	return f&features == features
+}
+
+func (f Feature) String() string {
+	names := make([]string, 0, len(featureNames))
+	for i, name := range featureNames {
+		if f.Has(Feature(1 << i)) {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Hello is a message which is exchanged at the start of a connection. It
+// describes the version of the protocol, the supported features and the
+// limits of a side.
+type Hello struct {
+	VersionMajor uint16
+	VersionMinor uint16
+	Features     Feature
+
+	// Limits. Zero maximum size of a response means no limit.
+	UidLenMax       uint16
+	UidListLenMax   uint16
+	RequestSizeMax  uint16
+	ResponseSizeMax uint32
+}
+
+// New creates a hello message of this implementation with the specified
+// maximum size of a response.
+func New(responseSizeMax uint32) (h *Hello) {
+	return &Hello{
+		VersionMajor:    protocol.VersionMajor,
+		VersionMinor:    protocol.VersionMinor,
+		Features:        Features_All,
+		UidLenMax:       protocol.UidLenMax,
+		UidListLenMax:   protocol.UidListLenMax,
+		RequestSizeMax:  math.MaxUint16,
+		ResponseSizeMax: responseSizeMax,
+	}
+}
+
+func NewFromBytes(ba []byte) (h *Hello, err error) {
+	if len(ba) < EncodedLen {
+		return nil, fmt.Errorf(ErrHelloIsTooShort, len(ba))
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	h = &Hello{
+		VersionMajor:    bo.Uint16(ba[0:2]),
+		VersionMinor:    bo.Uint16(ba[2:4]),
+		Features:        Feature(bo.Uint32(ba[4:8])),
+		UidLenMax:       bo.Uint16(ba[8:10]),
+		UidListLenMax:   bo.Uint16(ba[10:12]),
+		RequestSizeMax:  bo.Uint16(ba[12:14]),
+		ResponseSizeMax: bo.Uint32(ba[14:18]),
+	}
+
+	return h, nil
+}
+
+func (h *Hello) Bytes() (ba []byte, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	ba = make([]byte, 0, EncodedLen)
+	ba = bo.AppendUint16(ba, h.VersionMajor)
+	ba = bo.AppendUint16(ba, h.VersionMinor)
+	ba = bo.AppendUint32(ba, uint32(h.Features))
+	ba = bo.AppendUint16(ba, h.UidLenMax)
+	ba = bo.AppendUint16(ba, h.UidListLenMax)
+	ba = bo.AppendUint16(ba, h.RequestSizeMax)
+	ba = bo.AppendUint32(ba, h.ResponseSizeMax)
+
+	return ba, nil
+}
+
+// IsCompatibleWith checks whether the other side speaks a compatible version
+// of the protocol. Versions with different major numbers are not compatible.
+func (h *Hello) IsCompatibleWith(other *Hello) bool {
+	return h.VersionMajor == other.VersionMajor
+}
+
+// Version returns the version of the protocol as a text.
+func (h *Hello) Version() string {
+	return fmt.Sprintf("%d.%d", h.VersionMajor, h.VersionMinor)
+}
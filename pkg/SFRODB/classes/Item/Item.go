@@ -0,0 +1,61 @@
+package item
+
+import (
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
+)
+
+// Item is a single data record of a response with many data records.
+type Item struct {
+	// UID of the record.
+	// It is not transferred over the network, the client sets it using the
+	// order of UIDs in the request.
+	UID string
+
+	// Status of the record. Possible values are:
+	//	- 'Status_ShowingData' when the record is found;
+	//	- 'Status_FileDoesNotExist' when the record is not found;
+	//	- 'Status_ItemIsTooLarge' when the record is too large to be sent in
+	//	  a response with many records, it must be requested on its own;
+	//	- 'Status_ClientError' when the record can not be retrieved.
+	Status status.Status
+
+	// Data of the record when it is found, or a text of an error.
+	Data []byte
+
+	// ErrorCode is a machine-readable code of an error, it is set only with
+	// the 'Status_ClientError' status.
+	ErrorCode ec.ErrorCode
+}
+
+func New_Found(data []byte) (i *Item) {
+	return &Item{Status: status.Status_ShowingData, Data: data}
+}
+
+func New_NotFound() (i *Item) {
+	return &Item{Status: status.Status_FileDoesNotExist}
+}
+
+func New_TooLarge() (i *Item) {
+	return &Item{Status: status.Status_ItemIsTooLarge}
+}
+
+func New_Error(code ec.ErrorCode, msg string) (i *Item) {
+	return &Item{Status: status.Status_ClientError, Data: []byte(msg), ErrorCode: code}
+}
+
+func (i *Item) IsFound() bool {
+	return i.Status == status.Status_ShowingData
+}
+
+func (i *Item) IsNotFound() bool {
+	return i.Status == status.Status_FileDoesNotExist
+}
+
+func (i *Item) IsTooLarge() bool {
+	return i.Status == status.Status_ItemIsTooLarge
+}
+
+func (i *Item) IsError() bool {
+	return i.Status == status.Status_ClientError
+}
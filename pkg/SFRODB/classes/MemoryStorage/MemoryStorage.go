@@ -0,0 +1,142 @@
+package ms
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+)
+
+// MemoryStorage is a storage of data files in memory, see the
+// 'storage.Storage' interface. Files are put into it by the program, e.g. by
+// a test.
+type MemoryStorage struct {
+	files     map[string]memoryFile
+	filesLock *sync.RWMutex
+}
+
+// memoryFile is a file of the memory storage.
+type memoryFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+func New() (ms *MemoryStorage) {
+	return &MemoryStorage{
+		files:     make(map[string]memoryFile),
+		filesLock: new(sync.RWMutex),
+	}
+}
+
+// PutFile creates or replaces a file. The storage keeps its own copy of the
+// data.
+func (ms *MemoryStorage) PutFile(relPath string, data []byte) (err error) {
+	if !storage.IsRelPathValid(relPath) {
+		return errors.New(storage.ErrRelPathIsNotValid)
+	}
+
+	ms.filesLock.Lock()
+	defer ms.filesLock.Unlock()
+
+	ms.files[relPath] = memoryFile{
+		data:    append([]byte(nil), data...),
+		modTime: time.Now(),
+	}
+
+	return nil
+}
+
+// RemoveFile removes a file. Removing a missing file does nothing.
+func (ms *MemoryStorage) RemoveFile(relPath string) {
+	ms.filesLock.Lock()
+	defer ms.filesLock.Unlock()
+
+	delete(ms.files, relPath)
+}
+
+// getFile finds a file.
+func (ms *MemoryStorage) getFile(relPath string) (fileExists bool, mf memoryFile, err error) {
+	if !storage.IsRelPathValid(relPath) {
+		return false, mf, errors.New(storage.ErrRelPathIsNotValid)
+	}
+
+	ms.filesLock.RLock()
+	defer ms.filesLock.RUnlock()
+
+	mf, fileExists = ms.files[relPath]
+	if !fileExists {
+		return false, mf, fmt.Errorf(storage.ErrFileDoesNotExist, relPath)
+	}
+
+	return true, mf, nil
+}
+
+// OpenFile opens a file for reading and returns its size. Data of the file
+// is not copied, closing the file does nothing.
+func (ms *MemoryStorage) OpenFile(relPath string) (fileExists bool, f storage.File, fileSize int64, err error) {
+	var mf memoryFile
+	fileExists, mf, err = ms.getFile(relPath)
+	if !fileExists {
+		return false, nil, 0, err
+	}
+
+	return true, storage.NewBytesFile(mf.data), int64(len(mf.data)), nil
+}
+
+func (ms *MemoryStorage) FileExists(relPath string) (fileExists bool, err error) {
+	fileExists, _, err = ms.getFile(relPath)
+	if !fileExists && (err != nil) && (err.Error() == storage.ErrRelPathIsNotValid) {
+		return false, err
+	}
+
+	return fileExists, nil
+}
+
+// StatFile returns the state of a file without reading it.
+func (ms *MemoryStorage) StatFile(relPath string) (fileExists bool, state storage.FileState, err error) {
+	var mf memoryFile
+	fileExists, mf, err = ms.getFile(relPath)
+	if !fileExists {
+		return false, state, err
+	}
+
+	return true, storage.FileState{Size: int64(len(mf.data)), ModTime: mf.modTime}, nil
+}
+
+// ListFiles returns relative paths of all the files having the extension.
+// Paths are sorted.
+func (ms *MemoryStorage) ListFiles(extension string) (relPaths []string, err error) {
+	ms.filesLock.RLock()
+	defer ms.filesLock.RUnlock()
+
+	relPaths = make([]string, 0, len(ms.files))
+	for relPath := range ms.files {
+		if strings.HasSuffix(relPath, extension) {
+			relPaths = append(relPaths, relPath)
+		}
+	}
+
+	sort.Strings(relPaths)
+
+	return relPaths, nil
+}
+
+// ScanFiles returns states of all the files having the extension by their
+// relative paths.
+func (ms *MemoryStorage) ScanFiles(extension string) (states map[string]storage.FileState, err error) {
+	ms.filesLock.RLock()
+	defer ms.filesLock.RUnlock()
+
+	states = make(map[string]storage.FileState)
+	for relPath, mf := range ms.files {
+		if strings.HasSuffix(relPath, extension) {
+			states[relPath] = storage.FileState{Size: int64(len(mf.data)), ModTime: mf.modTime}
+		}
+	}
+
+	return states, nil
+}
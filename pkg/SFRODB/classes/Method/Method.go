@@ -2,19 +2,33 @@ package method
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
 )
 
 const (
-	Method_Unknown         = Method(0)
-	Method_CloseConnection = Method(1)
-	Method_ShowData        = Method(2)
-	Method_SearchRecord    = Method(3)
-	Method_SearchFile      = Method(4)
-	Method_ForgetRecord    = Method(5)
-	Method_ResetCache      = Method(6)
+	Method_Unknown            = Method(0)
+	Method_CloseConnection    = Method(1)
+	Method_ShowData           = Method(2)
+	Method_SearchRecord       = Method(3)
+	Method_SearchFile         = Method(4)
+	Method_ForgetRecord       = Method(5)
+	Method_ResetCache         = Method(6)
+	Method_ShowDataRange      = Method(7)
+	Method_ShowDataMany       = Method(8)
+	Method_StatRecord         = Method(9)
+	Method_ShowDataIfModified = Method(10)
+	Method_Hello              = Method(11)
+	Method_AuthChallenge      = Method(12)
+	Method_AuthResponse       = Method(13)
+	Method_Ping               = Method(14)
+	Method_ListRecords        = Method(15)
+	Method_Watch              = Method(16)
+	Method_WarmRecords        = Method(17)
+	Method_ShowCacheStats     = Method(18)
+	Method_ShowServerInfo     = Method(19)
 )
 
 const (
@@ -47,6 +61,45 @@ func NewFromString(str string) (m Method, err error) {
 	case protocol.Method_ResetCache:
 		return Method_ResetCache, nil
 
+	case protocol.Method_ShowDataRange:
+		return Method_ShowDataRange, nil
+
+	case protocol.Method_ShowDataMany:
+		return Method_ShowDataMany, nil
+
+	case protocol.Method_StatRecord:
+		return Method_StatRecord, nil
+
+	case protocol.Method_ShowDataIfModified:
+		return Method_ShowDataIfModified, nil
+
+	case protocol.Method_Hello:
+		return Method_Hello, nil
+
+	case protocol.Method_AuthChallenge:
+		return Method_AuthChallenge, nil
+
+	case protocol.Method_AuthResponse:
+		return Method_AuthResponse, nil
+
+	case protocol.Method_Ping:
+		return Method_Ping, nil
+
+	case protocol.Method_ListRecords:
+		return Method_ListRecords, nil
+
+	case protocol.Method_Watch:
+		return Method_Watch, nil
+
+	case protocol.Method_WarmRecords:
+		return Method_WarmRecords, nil
+
+	case protocol.Method_ShowCacheStats:
+		return Method_ShowCacheStats, nil
+
+	case protocol.Method_ShowServerInfo:
+		return Method_ShowServerInfo, nil
+
 	default:
 		return Method_Unknown, fmt.Errorf(ErrUnknownMethodName, methodStr)
 	}
@@ -72,7 +125,56 @@ func (m Method) Bytes() ([]byte, error) {
 	case Method_ResetCache:
 		return []byte(protocol.Method_ResetCache), nil
 
+	case Method_ShowDataRange:
+		return []byte(protocol.Method_ShowDataRange), nil
+
+	case Method_ShowDataMany:
+		return []byte(protocol.Method_ShowDataMany), nil
+
+	case Method_StatRecord:
+		return []byte(protocol.Method_StatRecord), nil
+
+	case Method_ShowDataIfModified:
+		return []byte(protocol.Method_ShowDataIfModified), nil
+
+	case Method_Hello:
+		return []byte(protocol.Method_Hello), nil
+
+	case Method_AuthChallenge:
+		return []byte(protocol.Method_AuthChallenge), nil
+
+	case Method_AuthResponse:
+		return []byte(protocol.Method_AuthResponse), nil
+
+	case Method_Ping:
+		return []byte(protocol.Method_Ping), nil
+
+	case Method_ListRecords:
+		return []byte(protocol.Method_ListRecords), nil
+
+	case Method_Watch:
+		return []byte(protocol.Method_Watch), nil
+
+	case Method_WarmRecords:
+		return []byte(protocol.Method_WarmRecords), nil
+
+	case Method_ShowCacheStats:
+		return []byte(protocol.Method_ShowCacheStats), nil
+
+	case Method_ShowServerInfo:
+		return []byte(protocol.Method_ShowServerInfo), nil
+
 	default:
-		return nil, fmt.Errorf(ErrUnknownMethodName, m)
+		return nil, fmt.Errorf(ErrUnknownMethodName, strconv.Itoa(int(m)))
 	}
 }
+
+// String returns the name of the method as it is used in the protocol.
+func (m Method) String() string {
+	ba, err := m.Bytes()
+	if err != nil {
+		return strconv.Itoa(int(m))
+	}
+
+	return string(ba)
+}
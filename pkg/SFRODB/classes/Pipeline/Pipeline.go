@@ -0,0 +1,397 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Connection"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Request"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Response"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
+)
+
+const (
+	ErrConnectionIsClosedByServer = "connection is closed by server"
+)
+
+// Pipeline is a client's side of a connection which allows to send requests
+// from several goroutines at the same time. Each request gets a unique ID.
+// Responses are read by a single reader and are delivered to the waiting
+// callers by their request IDs, so the server may answer requests in any
+// order. Data of a response may also be streamed to its caller, in this case
+// the reader waits until the caller has finished reading the data.
+type Pipeline struct {
+	con *connection.Connection
+
+	lastRequestId *atomic.Uint32
+
+	// Callers waiting for responses, by request ID.
+	waiters     map[uint32]*waiter
+	waitersLock *sync.Mutex
+
+	// Error which stopped the reader. When it is set, no more requests may be
+	// sent through the pipeline.
+	failure *ce.CommonError
+}
+
+// waiter is a caller waiting for a response.
+type waiter struct {
+	ch chan *result
+
+	// Status of a response which data must be streamed to the caller.
+	// Unknown status means that data is not streamed.
+	streamStatus status.Status
+
+	// Messages of a subscription. A subscriber receives all the messages
+	// having its request ID, it is not removed after the first message.
+	messages chan *response.Response
+}
+
+// result is what a waiting caller receives.
+type result struct {
+	resp *response.Response
+	body *body // Streamed data of the response, if any.
+	cerr *ce.CommonError
+}
+
+// New creates a pipeline over the connection and starts its reader.
+func New(con *connection.Connection) (p *Pipeline) {
+	p = &Pipeline{
+		con:           con,
+		lastRequestId: new(atomic.Uint32),
+		waiters:       make(map[uint32]*waiter),
+		waitersLock:   new(sync.Mutex),
+	}
+
+	go p.runReader()
+
+	return p
+}
+
+// Connection returns the underlying connection.
+func (p *Pipeline) Connection() (con *connection.Connection) {
+	return p.con
+}
+
+// IsBroken tells whether the pipeline can not be used any more, i.e. its
+// connection is broken or its reader has stopped.
+func (p *Pipeline) IsBroken() (isBroken bool) {
+	if p.con.IsBroken() {
+		return true
+	}
+
+	p.waitersLock.Lock()
+	defer p.waitersLock.Unlock()
+
+	return p.failure != nil
+}
+
+// Send sends a request without waiting for a response.
+// Returns a detailed error.
+func (p *Pipeline) Send(req *request.Request) (cerr *ce.CommonError) {
+	return p.SendCtx(context.Background(), req)
+}
+
+// SendCtx sends a request without waiting for a response. The deadline of the
+// context is used as a deadline for writing into the connection.
+// Returns a detailed error.
+func (p *Pipeline) SendCtx(ctx context.Context, req *request.Request) (cerr *ce.CommonError) {
+	if ctx.Err() != nil {
+		return p.newContextError(ctx, req)
+	}
+
+	req.Id = p.nextRequestId()
+
+	deadline, _ := ctx.Deadline()
+	cerr = p.con.SendRequestMessageWithDeadline(req, deadline)
+	if (cerr != nil) && (ctx.Err() != nil) {
+		// The message may be incomplete.
+		_ = p.con.Break()
+		return p.newContextError(ctx, req)
+	}
+
+	return cerr
+}
+
+// RoundTrip sends a request and waits for a response to it.
+// Returns a detailed error.
+func (p *Pipeline) RoundTrip(req *request.Request) (resp *response.Response, cerr *ce.CommonError) {
+	return p.RoundTripCtx(context.Background(), req)
+}
+
+// RoundTripCtx sends a request and waits for a response to it. When the
+// context is done before the response is received, the connection is broken,
+// because the rest of the conversation can not be trusted any more. The
+// deadline of the context is also used as a deadline for writing into the
+// connection.
+// Returns a detailed error.
+func (p *Pipeline) RoundTripCtx(ctx context.Context, req *request.Request) (resp *response.Response, cerr *ce.CommonError) {
+	var res *result
+	res, cerr = p.roundTrip(ctx, req, status.Status_Unknown)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	return res.resp, nil
+}
+
+// Subscribe sends a request which is answered by many messages having the
+// same request ID, e.g. events. Messages are delivered to the returned channel
+// until the pipeline stops, then the channel is closed. The reader waits until
+// each message is received, so a pipeline with a subscription should not be
+// used for other requests.
+// Returns a detailed error.
+func (p *Pipeline) Subscribe(req *request.Request) (messages <-chan *response.Response, cerr *ce.CommonError) {
+	req.Id = p.nextRequestId()
+
+	var w *waiter
+	w, cerr = p.addSubscriber(req.Id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	cerr = p.con.SendRequestMessage(req)
+	if cerr != nil {
+		p.removeWaiter(req.Id)
+		return nil, cerr
+	}
+
+	return w.messages, nil
+}
+
+// RoundTripStream sends a request and waits for a response to it. When the
+// response has the specified status, its data is not read, a reader of the
+// data is returned instead. The reader must be closed by the caller, no other
+// responses are received until then. For responses with other statuses the
+// reader is nil and data is read as usual.
+// Returns a detailed error.
+func (p *Pipeline) RoundTripStream(req *request.Request, streamStatus status.Status) (resp *response.Response, data io.ReadCloser, cerr *ce.CommonError) {
+	return p.RoundTripStreamCtx(context.Background(), req, streamStatus)
+}
+
+// RoundTripStreamCtx is the 'RoundTripStream' method which uses the context,
+// see the 'RoundTripCtx' method. A streamed response is finished when its
+// reader is closed.
+// Returns a detailed error.
+func (p *Pipeline) RoundTripStreamCtx(ctx context.Context, req *request.Request, streamStatus status.Status) (resp *response.Response, data io.ReadCloser, cerr *ce.CommonError) {
+	var res *result
+	res, cerr = p.roundTrip(ctx, req, streamStatus)
+	if cerr != nil {
+		return nil, nil, cerr
+	}
+
+	if res.body == nil {
+		return res.resp, nil, nil
+	}
+
+	return res.resp, res.body, nil
+}
+
+func (p *Pipeline) roundTrip(ctx context.Context, req *request.Request, streamStatus status.Status) (res *result, cerr *ce.CommonError) {
+	if ctx.Err() != nil {
+		return nil, p.newContextError(ctx, req)
+	}
+
+	req.Id = p.nextRequestId()
+
+	var w *waiter
+	w, cerr = p.addWaiter(req.Id, streamStatus)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	// Breaking the connection unblocks both writing and reading.
+	stopBreaker := context.AfterFunc(ctx, func() {
+		_ = p.con.Break()
+	})
+
+	deadline, _ := ctx.Deadline()
+	cerr = p.con.SendRequestMessageWithDeadline(req, deadline)
+	if cerr != nil {
+		stopBreaker()
+		p.removeWaiter(req.Id)
+		if ctx.Err() != nil {
+			return nil, p.newContextError(ctx, req)
+		}
+		return nil, cerr
+	}
+
+	select {
+	case res = <-w.ch:
+	case <-ctx.Done():
+		// The breaker may not have finished yet.
+		_ = p.con.Break()
+		if p.removeWaiter(req.Id) == nil {
+			// The response has come at the same time.
+			res = <-w.ch
+			if res.body != nil {
+				_ = res.body.Close()
+			}
+		}
+		return nil, p.newContextError(ctx, req)
+	}
+
+	if res.cerr != nil {
+		stopBreaker()
+		if ctx.Err() != nil {
+			return nil, p.newContextError(ctx, req)
+		}
+		return nil, res.cerr
+	}
+
+	if res.body != nil {
+		res.body.onClose = stopBreaker
+	} else {
+		stopBreaker()
+	}
+
+	return res, nil
+}
+
+// newContextError creates an error of a call which context is done.
+func (p *Pipeline) newContextError(ctx context.Context, req *request.Request) (cerr *ce.CommonError) {
+	return ce.NewClientError(ctx.Err().Error(), req.Method, 0, p.con.ClientId())
+}
+
+// nextRequestId returns a new request ID. Zero ID is reserved for messages
+// which are not related to any request.
+func (p *Pipeline) nextRequestId() (requestId uint32) {
+	for {
+		requestId = p.lastRequestId.Add(1)
+		if requestId != protocol.RequestIdNone {
+			return requestId
+		}
+	}
+}
+
+func (p *Pipeline) addWaiter(requestId uint32, streamStatus status.Status) (w *waiter, cerr *ce.CommonError) {
+	p.waitersLock.Lock()
+	defer p.waitersLock.Unlock()
+
+	if p.failure != nil {
+		return nil, p.failure
+	}
+
+	w = &waiter{
+		ch:           make(chan *result, 1),
+		streamStatus: streamStatus,
+	}
+	p.waiters[requestId] = w
+	return w, nil
+}
+
+func (p *Pipeline) addSubscriber(requestId uint32) (w *waiter, cerr *ce.CommonError) {
+	p.waitersLock.Lock()
+	defer p.waitersLock.Unlock()
+
+	if p.failure != nil {
+		return nil, p.failure
+	}
+
+	w = &waiter{
+		messages: make(chan *response.Response, 1),
+	}
+	p.waiters[requestId] = w
+	return w, nil
+}
+
+// takeWaiter finds the waiter of a response. Waiters are removed, except
+// subscribers.
+func (p *Pipeline) takeWaiter(requestId uint32) (w *waiter) {
+	p.waitersLock.Lock()
+	defer p.waitersLock.Unlock()
+
+	w = p.waiters[requestId]
+	if (w != nil) && (w.messages == nil) {
+		delete(p.waiters, requestId)
+	}
+	return w
+}
+
+func (p *Pipeline) removeWaiter(requestId uint32) (w *waiter) {
+	p.waitersLock.Lock()
+	defer p.waitersLock.Unlock()
+
+	w = p.waiters[requestId]
+	delete(p.waiters, requestId)
+	return w
+}
+
+// runReader reads responses and delivers them to the waiting callers. When
+// reading fails, all the waiting callers receive the error.
+func (p *Pipeline) runReader() {
+	var resp *response.Response
+	var cerr *ce.CommonError
+
+	var w *waiter
+
+	for {
+		resp, cerr = p.con.GetResponseHeader()
+		if cerr != nil {
+			p.fail(cerr)
+			return
+		}
+
+		w = p.takeWaiter(resp.RequestId)
+
+		if (w != nil) && (w.streamStatus != status.Status_Unknown) && (w.streamStatus == resp.Status) {
+			b := newBody(p.con.GetResponseDataReader(resp), p.con.ClientId())
+			w.ch <- &result{resp: resp, body: b}
+
+			cerr = b.wait()
+			if cerr != nil {
+				p.fail(cerr)
+				return
+			}
+
+			continue
+		}
+
+		cerr = p.con.GetResponseData(resp)
+		if cerr != nil {
+			if (w != nil) && (w.messages == nil) {
+				w.ch <- &result{cerr: cerr}
+			}
+			p.fail(cerr)
+			return
+		}
+
+		if (resp.RequestId == protocol.RequestIdNone) && (resp.Status == status.Status_ClosingConnection) {
+			p.fail(ce.NewClientError(ErrConnectionIsClosedByServer, 0, resp.Status, p.con.ClientId()))
+			return
+		}
+
+		if w == nil {
+			// Nobody waits for this response.
+			continue
+		}
+
+		if w.messages != nil {
+			w.messages <- resp
+			continue
+		}
+
+		w.ch <- &result{resp: resp}
+	}
+}
+
+// fail stops the pipeline. All the waiting callers receive the error.
+// Channels of subscribers are closed. This method is used only by the reader.
+func (p *Pipeline) fail(cerr *ce.CommonError) {
+	p.waitersLock.Lock()
+	defer p.waitersLock.Unlock()
+
+	p.failure = cerr
+	for requestId, w := range p.waiters {
+		if w.messages != nil {
+			close(w.messages)
+		} else {
+			w.ch <- &result{cerr: cerr}
+		}
+		delete(p.waiters, requestId)
+	}
+}
@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"io"
+	"sync"
+
+	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+)
+
+// body is a reader of response's data which is streamed to the caller.
+// Closing the body skips its unread data and lets the pipeline's reader
+// continue with the next response.
+type body struct {
+	rdr      io.Reader
+	clientId string
+
+	closeOnce *sync.Once
+	closeErr  error
+	isClosed  chan *ce.CommonError
+
+	// Function which is called when the body is closed, if it is set.
+	onClose func() bool
+}
+
+func newBody(rdr io.Reader, clientId string) (b *body) {
+	return &body{
+		rdr:       rdr,
+		clientId:  clientId,
+		closeOnce: new(sync.Once),
+		isClosed:  make(chan *ce.CommonError, 1),
+	}
+}
+
+// Read is the standard method of the 'io.Reader' interface.
+func (b *body) Read(dst []byte) (n int, err error) {
+	return b.rdr.Read(dst)
+}
+
+// Close is the standard method of the 'io.Closer' interface.
+func (b *body) Close() (err error) {
+	b.closeOnce.Do(func() {
+		// Unread data must be skipped.
+		_, b.closeErr = io.Copy(io.Discard, b.rdr)
+		if b.closeErr != nil {
+			b.isClosed <- ce.NewClientError(b.closeErr.Error(), 0, 0, b.clientId)
+		} else {
+			b.isClosed <- nil
+		}
+
+		if b.onClose != nil {
+			b.onClose()
+		}
+	})
+
+	return b.closeErr
+}
+
+// wait waits until the body is closed.
+func (b *body) wait() (cerr *ce.CommonError) {
+	return <-b.isClosed
+}
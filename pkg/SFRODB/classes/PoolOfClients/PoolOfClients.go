@@ -1,6 +1,7 @@
 package poc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -12,12 +13,22 @@ import (
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Client"
 	cs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ClientSettings"
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Item"
+	ae "github.com/vault-thirteen/auxie/errors"
 )
 
 const (
 	ClientRestarterSuccessPauseSec = 5
 	ClientRestarterFailurePauseSec = 15
 
+	// Idle clients are checked by the health checker periodically.
+	HealthCheckerPauseSec = 30
+
+	// A client which has been idle for a longer time is checked before it is
+	// given away. Clients which have been used recently are known to be
+	// alive.
+	IdleClientCheckThresholdSec = 10
+
 	ErrDuplicateClientId       = "duplicate client id: %v"
 	ErrNoIdleClientIsAvailable = "no idle client is available"
 	ErrClientIsNotBeingUsed    = "client is not being used: %v"
@@ -30,6 +41,9 @@ type PoolOfClients struct {
 	// Clients with established connection, ready to be used.
 	idleClients chan *client.Client
 
+	// Time when each client became idle, by client ID.
+	idleSince map[string]time.Time
+
 	// Clients which are actively used at the moment.
 	usedClients map[string]*client.Client
 
@@ -51,6 +65,7 @@ func New(size int, stn *cs.ClientSettings) (pool *PoolOfClients, err error) {
 	pool = &PoolOfClients{
 		size:            size,
 		idleClients:     make(chan *client.Client, size),
+		idleSince:       make(map[string]time.Time),
 		usedClients:     make(map[string]*client.Client),
 		brokenClients:   make(chan *client.Client, size),
 		stoppedClients:  make(chan *client.Client, size),
@@ -106,11 +121,12 @@ func (cp *PoolOfClients) Start() (cerr *ce.CommonError) {
 
 	// Save all the started clients.
 	for _, cli := range clientsToStart {
-		cp.idleClients <- cli
+		cp.putIdleClient(cli)
 	}
 
-	cp.subRoutines.Add(1)
+	cp.subRoutines.Add(2)
 	go cp.clientRestarter()
+	go cp.healthChecker()
 
 	log.Printf("A pool of %d clients has been started.\r\n", cp.size)
 
@@ -174,11 +190,92 @@ func (cp *PoolOfClients) clientRestarterCore() (success bool) {
 	}
 
 	log.Println("A broken client was successfully reconnected.")
-	cp.idleClients <- cli
+	cp.putIdleClient(cli)
 	return true
 }
 
+// healthChecker periodically checks idle clients. Clients which do not answer
+// a ping are moved to the 'brokenClients' channel.
+func (cp *PoolOfClients) healthChecker() {
+	defer cp.subRoutines.Done()
+
+	for {
+		for i := 1; i <= HealthCheckerPauseSec; i++ {
+			if cp.mustStop.Load() {
+				break
+			}
+			time.Sleep(time.Second)
+		}
+		if cp.mustStop.Load() {
+			break
+		}
+
+		cp.checkIdleClients()
+	}
+
+	log.Println("Health checker has stopped.")
+}
+
+// checkIdleClients checks each client which has been idle for a long time.
+// Clients are taken from the pool while they are being checked.
+func (cp *PoolOfClients) checkIdleClients() {
+	cp.clientTransfers.Lock()
+	n := len(cp.idleClients)
+	cp.clientTransfers.Unlock()
+
+	var cli *client.Client
+	var mustBeChecked bool
+	var err error
+	for i := 1; i <= n; i++ {
+		if cp.mustStop.Load() {
+			return
+		}
+
+		cli, mustBeChecked, err = cp.giveIdleClient()
+		if err != nil {
+			return
+		}
+
+		if mustBeChecked {
+			cp.checkClient(cli)
+			continue
+		}
+
+		err = cp.TakeIdleClient(cli.GetId(), false)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// checkClient pings the client taken from the pool and returns it into the
+// pool. A client which does not answer is returned as broken.
+func (cp *PoolOfClients) checkClient(cli *client.Client) {
+	cerr := cli.Ping()
+	if cerr != nil {
+		log.Printf("Client [%s] is broken: %s", cli.GetId(), cerr.Error())
+	}
+
+	err := cp.TakeIdleClient(cli.GetId(), cerr != nil)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
 func (cp *PoolOfClients) Stop() {
+	cp.stopClients()
+
+	// Subroutines may need the lock to finish their work, so they are waited
+	// for without it.
+	log.Println("Waiting for subroutines to stop ...")
+	cp.subRoutines.Wait()
+
+	log.Println("Client pool has been stopped.")
+}
+
+// stopClients stops all the clients and moves them into the 'stoppedClients'
+// channel.
+func (cp *PoolOfClients) stopClients() {
 	cp.clientTransfers.Lock()
 	defer cp.clientTransfers.Unlock()
 
@@ -190,6 +287,7 @@ func (cp *PoolOfClients) Stop() {
 	var cli *client.Client
 	for len(cp.idleClients) > 0 {
 		cli = <-cp.idleClients
+		delete(cp.idleSince, cli.GetId())
 		_ = cli.Stop()
 		log.Printf("Client [%s] was stopped.", cli.GetId())
 		cp.stoppedClients <- cli
@@ -207,21 +305,44 @@ func (cp *PoolOfClients) Stop() {
 		log.Printf("Client [%s] was stopped.", cli.GetId())
 		cp.stoppedClients <- cli
 	}
-
-	log.Println("Waiting for subroutines to stop ...")
-	cp.subRoutines.Wait()
-
-	log.Println("Client pool has been stopped.")
 }
 
 // GiveIdleClient provides an idle client. If no clients are idle, an error is
-// returned.
+// returned. A client which has been idle for a long time is checked before it
+// is given away, broken clients are skipped.
 func (cp *PoolOfClients) GiveIdleClient() (cli *client.Client, err error) {
+	var mustBeChecked bool
+	for {
+		cli, mustBeChecked, err = cp.giveIdleClient()
+		if err != nil {
+			return nil, err
+		}
+
+		if !mustBeChecked {
+			return cli, nil
+		}
+
+		cerr := cli.Ping()
+		if cerr == nil {
+			return cli, nil
+		}
+
+		log.Printf("Client [%s] is broken: %s", cli.GetId(), cerr.Error())
+		err = cp.TakeIdleClient(cli.GetId(), true)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// giveIdleClient takes an idle client from the pool and tells whether it has
+// been idle for so long that it must be checked.
+func (cp *PoolOfClients) giveIdleClient() (cli *client.Client, mustBeChecked bool, err error) {
 	cp.clientTransfers.Lock()
 	defer cp.clientTransfers.Unlock()
 
 	if len(cp.idleClients) == 0 {
-		return nil, errors.New(ErrNoIdleClientIsAvailable)
+		return nil, false, errors.New(ErrNoIdleClientIsAvailable)
 	}
 
 	cli = <-cp.idleClients
@@ -229,23 +350,30 @@ func (cp *PoolOfClients) GiveIdleClient() (cli *client.Client, err error) {
 	if idExists {
 		// Clients may not have same IDs !
 		cp.idleClients <- cli
-		return nil, fmt.Errorf(ErrDuplicateClientId, cli.GetId())
+		return nil, false, fmt.Errorf(ErrDuplicateClientId, cli.GetId())
 	}
 
+	mustBeChecked = time.Since(cp.idleSince[cli.GetId()]) > time.Second*IdleClientCheckThresholdSec
+	delete(cp.idleSince, cli.GetId())
+
 	cp.usedClients[cli.GetId()] = cli
-	return cli, nil
+	return cli, mustBeChecked, nil
+}
+
+// putIdleClient puts the client into the 'idleClients' channel and remembers
+// when it became idle. The caller must hold the 'clientTransfers' lock.
+func (cp *PoolOfClients) putIdleClient(cli *client.Client) {
+	cp.idleSince[cli.GetId()] = time.Now()
+	cp.idleClients <- cli
 }
 
 // TakeIdleClient receives an idle client.
-// While current version of the Go programming language does not allow to use
-// methods applicable to older versions of the language, now we can not know
-// whether the client is functioning or broken. In previous versions of Go
-// it was possible to know the state of the network connection by reading it
-// into an empty slice. By the way, TCP Keep Alive packets also use empty data
-// field, but they are not accessible in Golang directly. All this means that
-// we should get information about the client's connection state from an outer
-// world, so we receive an 'isBroken' flag. I hope that some day developers of
-// Go language will make something working.
+// The caller tells whether the client is broken with the 'isBroken' flag,
+// e.g. when a request has failed with a server's error. A client which
+// connection has been broken, e.g. by a cancelled call, is treated as broken
+// regardless of the flag. Clients which broke while being idle are found by
+// the pool itself with the ping method, see the 'healthChecker' and
+// 'GiveIdleClient' methods.
 func (cp *PoolOfClients) TakeIdleClient(clientId string, isBroken bool) (err error) {
 	cp.clientTransfers.Lock()
 	defer cp.clientTransfers.Unlock()
@@ -253,15 +381,42 @@ func (cp *PoolOfClients) TakeIdleClient(clientId string, isBroken bool) (err err
 	cli, idExists := cp.usedClients[clientId]
 	if !idExists {
 		// Client is not being used !
-		return fmt.Errorf(ErrClientIsNotBeingUsed, cli.GetId())
+		return fmt.Errorf(ErrClientIsNotBeingUsed, clientId)
 	}
 
-	if isBroken {
+	if isBroken || cli.IsBroken() {
 		cp.brokenClients <- cli
 	} else {
-		cp.idleClients <- cli
+		cp.putIdleClient(cli)
 	}
 	delete(cp.usedClients, clientId)
 
 	return nil
 }
+
+// ShowDataMany requests many data records from server using an idle client.
+// The client is returned to the pool afterwards.
+// Returns a detailed error.
+func (cp *PoolOfClients) ShowDataMany(uids []string) (items []*item.Item, cerr *ce.CommonError) {
+	return cp.ShowDataManyCtx(context.Background(), uids)
+}
+
+// ShowDataManyCtx is the 'ShowDataMany' method which uses the context.
+// Returns a detailed error.
+func (cp *PoolOfClients) ShowDataManyCtx(ctx context.Context, uids []string) (items []*item.Item, cerr *ce.CommonError) {
+	cli, err := cp.GiveIdleClient()
+	if err != nil {
+		return nil, ce.NewClientError(err.Error(), 0, 0, client.ClientIdNone)
+	}
+
+	defer func() {
+		isBroken := (cerr != nil) && cerr.IsServerError()
+		err = cp.TakeIdleClient(cli.GetId(), isBroken)
+		if err != nil {
+			combinedError := ae.Combine(cerr, err)
+			cerr = ce.NewClientError(combinedError.Error(), 0, 0, cli.GetId())
+		}
+	}()
+
+	return cli.ShowDataManyCtx(ctx, uids)
+}
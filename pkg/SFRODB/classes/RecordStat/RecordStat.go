@@ -0,0 +1,36 @@
+package rs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	SizeLen     = 8
+	ModTimeLen  = 8
+	HashLen     = sha256.Size
+	IsCachedLen = 1
+
+	EncodedLen = SizeLen + ModTimeLen + HashLen + IsCachedLen
+)
+
+// RecordStat is metadata of a data record.
+type RecordStat struct {
+	// Size of the record in bytes.
+	Size uint64
+
+	// Time of the last modification of the record's file.
+	ModTime time.Time
+
+	// SHA-256 hash sum of the record's contents.
+	Hash [HashLen]byte
+
+	// Whether the record is currently stored in the cache.
+	IsCached bool
+}
+
+// HashString returns the hash sum as a hexadecimal text.
+func (rs *RecordStat) HashString() string {
+	return hex.EncodeToString(rs.Hash[:])
+}
@@ -1,20 +1,51 @@
 package request
 
 import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Auth"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Endianness"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Hello"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Method"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/UID"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
 )
 
 const (
-	ErrSizeIsTooShort = "request size is too short: %v"
-	ErrSizeIsTooLong  = "request size is too long: %v"
+	ErrSizeIsTooShort       = "request size is too short: %v"
+	ErrSizeIsTooLong        = "request size is too long: %v"
+	ErrUidSizeIsTooLong     = "UID size is too long: %v"
+	ErrParametersAreInvalid = "request parameters are invalid"
+	ErrUidListIsEmpty       = "UID list is empty"
+	ErrUidListIsTooLong     = "UID list is too long: %v"
+	ErrTokenIsTooLong       = "version token is too long: %v"
+	ErrLimitIsTooLarge      = "limit is too large: %v"
+)
+
+const (
+	RangeParametersLen = 16
+
+	// ListLimitLen is the length of a limit of a page of a list of records.
+	ListLimitLen = 2
 )
 
 type Request struct {
-	Size   uint16 // Length of method name + UID string.
+	// Length of method name + request ID + UID size + UID string +
+	// parameters.
+	Size   uint16
 	Method method.Method
-	UID    *uid.UID // UID of the requested item.
+
+	// ID of the request. It is set by the client before the request is sent.
+	// Server puts the same ID into the response, so that the client is able
+	// to match responses with requests when they are pipelined.
+	Id uint32
+
+	UID *uid.UID // UID of the requested item.
+
+	// Parameters of the request. Their format depends on the method.
+	Parameters []byte
 }
 
 func (r *Request) IsCloseConnection() bool {
@@ -25,10 +56,59 @@ func New_CloseConnection() (req *Request, err error) {
 	return newSimpleRequest(method.Method_CloseConnection)
 }
 
+func New_Ping() (req *Request, err error) {
+	return newSimpleRequest(method.Method_Ping)
+}
+
 func New_ResetCache() (req *Request, err error) {
 	return newSimpleRequest(method.Method_ResetCache)
 }
 
+func New_ShowCacheStats() (req *Request, err error) {
+	return newSimpleRequest(method.Method_ShowCacheStats)
+}
+
+func New_ShowServerInfo() (req *Request, err error) {
+	return newSimpleRequest(method.Method_ShowServerInfo)
+}
+
+func New_Watch() (req *Request, err error) {
+	return newSimpleRequest(method.Method_Watch)
+}
+
+func New_Hello(h *hello.Hello) (req *Request, err error) {
+	var params []byte
+	params, err = h.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return newRequestWithParameters(method.Method_Hello, "", params)
+}
+
+// GetHello returns the hello message of a client.
+func (r *Request) GetHello() (h *hello.Hello, err error) {
+	return hello.NewFromBytes(r.Parameters)
+}
+
+func New_AuthChallenge() (req *Request, err error) {
+	return newSimpleRequest(method.Method_AuthChallenge)
+}
+
+// New_AuthResponse creates a request with the client's answer to a challenge.
+func New_AuthResponse(signature []byte) (req *Request, err error) {
+	return newRequestWithParameters(method.Method_AuthResponse, "", signature)
+}
+
+// GetSignature returns the client's answer to a challenge.
+func (r *Request) GetSignature() (signature []byte, err error) {
+	if len(r.Parameters) != auth.SignatureLen {
+		return nil, errors.New(ErrParametersAreInvalid)
+	}
+
+	return r.Parameters, nil
+}
+
 func New_ShowData(requestedUID string) (req *Request, err error) {
 	return newNormalRequest(method.Method_ShowData, requestedUID)
 }
@@ -41,28 +121,292 @@ func New_SearchFile(requestedUID string) (req *Request, err error) {
 	return newNormalRequest(method.Method_SearchFile, requestedUID)
 }
 
+func New_StatRecord(requestedUID string) (req *Request, err error) {
+	return newNormalRequest(method.Method_StatRecord, requestedUID)
+}
+
 func New_ForgetRecord(requestedUID string) (req *Request, err error) {
 	return newNormalRequest(method.Method_ForgetRecord, requestedUID)
 }
 
+// New_ShowDataRange creates a request for a part of a data record. The part
+// starts at the offset and has the specified length. Length is truncated by
+// server when the part exceeds the end of the record.
+func New_ShowDataRange(requestedUID string, offset uint64, length uint64) (req *Request, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]byte, RangeParametersLen)
+	bo.PutUint64(params[0:8], offset)
+	bo.PutUint64(params[8:16], length)
+
+	return newRequestWithParameters(method.Method_ShowDataRange, requestedUID, params)
+}
+
+// GetRangeParameters reads parameters of a request for a part of a data
+// record.
+func (r *Request) GetRangeParameters() (offset uint64, length uint64, err error) {
+	if len(r.Parameters) != RangeParametersLen {
+		return 0, 0, errors.New(ErrParametersAreInvalid)
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return bo.Uint64(r.Parameters[0:8]), bo.Uint64(r.Parameters[8:16]), nil
+}
+
+// New_ShowDataIfModified creates a request for a data record which is
+// fulfilled only when the version token of the record differs from the
+// specified one. An empty token means that client has no copy of the record.
+func New_ShowDataIfModified(requestedUID string, token []byte) (req *Request, err error) {
+	if len(token) > protocol.VersionTokenLenMax {
+		return nil, fmt.Errorf(ErrTokenIsTooLong, len(token))
+	}
+
+	return newRequestWithParameters(method.Method_ShowDataIfModified, requestedUID, token)
+}
+
+// GetVersionToken returns the version token of a conditional request.
+func (r *Request) GetVersionToken() (token []byte, err error) {
+	if len(r.Parameters) > protocol.VersionTokenLenMax {
+		return nil, errors.New(ErrParametersAreInvalid)
+	}
+
+	return r.Parameters, nil
+}
+
+// New_ShowDataMany creates a request for many data records.
+func New_ShowDataMany(requestedUIDs []string) (req *Request, err error) {
+	var params []byte
+	params, err = encodeUidList(requestedUIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRequestWithParameters(method.Method_ShowDataMany, "", params)
+}
+
+// GetUidList reads a list of UIDs from parameters of a request.
+func (r *Request) GetUidList() (uids []*uid.UID, err error) {
+	return decodeUidList(r.Parameters)
+}
+
+// New_WarmRecords creates a request which loads the listed records into
+// cache.
+func New_WarmRecords(requestedUIDs []string) (req *Request, err error) {
+	var params []byte
+	params, err = encodeUidList(requestedUIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRequestWithParameters(method.Method_WarmRecords, "", params)
+}
+
+// New_WarmRecordsWithPrefix creates a request which loads into cache all the
+// records which UIDs start with the prefix. An empty prefix means all the
+// records.
+func New_WarmRecordsWithPrefix(prefix string) (req *Request, err error) {
+	return newRequestWithParameters(method.Method_WarmRecords, prefix, nil)
+}
+
+// GetWarmUpParameters reads parameters of a request for a warm-up of cache.
+// When the request has no list of UIDs, the UID of the request is a prefix
+// and the returned list is nil.
+func (r *Request) GetWarmUpParameters() (uids []*uid.UID, err error) {
+	if len(r.Parameters) == 0 {
+		return nil, nil
+	}
+
+	if r.UID.Length() > 0 {
+		return nil, errors.New(ErrParametersAreInvalid)
+	}
+
+	return decodeUidList(r.Parameters)
+}
+
+// encodeUidList encodes a list of UIDs. Count of UIDs goes first, then each
+// UID is preceded by its size.
+func encodeUidList(uids []string) (ba []byte, err error) {
+	if len(uids) == 0 {
+		return nil, errors.New(ErrUidListIsEmpty)
+	}
+	if len(uids) > protocol.UidListLenMax {
+		return nil, fmt.Errorf(ErrUidListIsTooLong, len(uids))
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	ba = bo.AppendUint16(nil, uint16(len(uids)))
+
+	var u *uid.UID
+	for _, s := range uids {
+		u, err = uid.New(s)
+		if err != nil {
+			return nil, err
+		}
+
+		ba = append(ba, byte(u.Length()))
+		ba = append(ba, u.Bytes()...)
+	}
+
+	return ba, nil
+}
+
+// decodeUidList decodes a list of UIDs.
+func decodeUidList(ba []byte) (uids []*uid.UID, err error) {
+	if len(ba) < 2 {
+		return nil, errors.New(ErrParametersAreInvalid)
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	count := int(bo.Uint16(ba[0:2]))
+	if count == 0 {
+		return nil, errors.New(ErrUidListIsEmpty)
+	}
+	if count > protocol.UidListLenMax {
+		return nil, fmt.Errorf(ErrUidListIsTooLong, count)
+	}
+
+	uids = make([]*uid.UID, 0, count)
+	var u *uid.UID
+	var uidSize int
+	pos := 2
+	for i := 0; i < count; i++ {
+		if pos >= len(ba) {
+			return nil, errors.New(ErrParametersAreInvalid)
+		}
+
+		uidSize = int(ba[pos])
+		pos++
+		if pos+uidSize > len(ba) {
+			return nil, errors.New(ErrParametersAreInvalid)
+		}
+
+		u, err = uid.New(string(ba[pos : pos+uidSize]))
+		if err != nil {
+			return nil, err
+		}
+
+		uids = append(uids, u)
+		pos += uidSize
+	}
+
+	if pos != len(ba) {
+		return nil, errors.New(ErrParametersAreInvalid)
+	}
+
+	return uids, nil
+}
+
+// New_ListRecords creates a request for a page of a list of records. Only
+// UIDs starting with the prefix are listed, an empty prefix lists all the
+// records. The page starts after the cursor, i.e. after the last UID of the
+// previous page, an empty cursor starts the list. Zero limit means the
+// maximum size of a page.
+func New_ListRecords(prefix string, cursor string, limit uint16) (req *Request, err error) {
+	if limit > protocol.RecordListLenMax {
+		return nil, fmt.Errorf(ErrLimitIsTooLarge, limit)
+	}
+
+	var c *uid.UID
+	c, err = uid.New(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]byte, 0, protocol.UidSizeLen+c.Length()+ListLimitLen)
+	params = append(params, byte(c.Length()))
+	params = append(params, c.Bytes()...)
+	params = bo.AppendUint16(params, limit)
+
+	return newRequestWithParameters(method.Method_ListRecords, prefix, params)
+}
+
+// GetListParameters returns the cursor and the limit of a request for a page
+// of a list of records. The prefix is the UID of the request.
+func (r *Request) GetListParameters() (cursor *uid.UID, limit int, err error) {
+	if len(r.Parameters) < protocol.UidSizeLen+ListLimitLen {
+		return nil, 0, errors.New(ErrParametersAreInvalid)
+	}
+
+	cursorSize := int(r.Parameters[0])
+	if len(r.Parameters) != protocol.UidSizeLen+cursorSize+ListLimitLen {
+		return nil, 0, errors.New(ErrParametersAreInvalid)
+	}
+
+	cursor, err = uid.New(string(r.Parameters[protocol.UidSizeLen : protocol.UidSizeLen+cursorSize]))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	limit = int(bo.Uint16(r.Parameters[protocol.UidSizeLen+cursorSize:]))
+	if limit > protocol.RecordListLenMax {
+		return nil, 0, fmt.Errorf(ErrLimitIsTooLarge, limit)
+	}
+	if limit == 0 {
+		limit = protocol.RecordListLenMax
+	}
+
+	return cursor, limit, nil
+}
+
 func newSimpleRequest(method method.Method) (req *Request, err error) {
 	return &Request{
-		Size:   protocol.MethodNameLen,
+		Size:   protocol.MethodNameLen + protocol.RequestIdLen + protocol.UidSizeLen,
 		Method: method,
 	}, nil
 }
 
 func newNormalRequest(method method.Method, requestedUID string) (req *Request, err error) {
+	return newRequestWithParameters(method, requestedUID, nil)
+}
+
+func newRequestWithParameters(method method.Method, requestedUID string, params []byte) (req *Request, err error) {
 	var u *uid.UID
 	u, err = uid.New(requestedUID)
 	if err != nil {
 		return nil, err
 	}
 
+	rs := protocol.MethodNameLen + protocol.RequestIdLen + protocol.UidSizeLen + u.Length() + len(params)
+	if rs > math.MaxUint16 {
+		return nil, fmt.Errorf(ErrSizeIsTooLong, rs)
+	}
+
 	req = &Request{
-		Size:   uint16(protocol.MethodNameLen + u.Length()),
-		Method: method,
-		UID:    u,
+		Size:       uint16(rs),
+		Method:     method,
+		UID:        u,
+		Parameters: params,
 	}
 
 	return req, nil
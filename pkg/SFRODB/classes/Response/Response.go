@@ -1,8 +1,20 @@
 package response
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Auth"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Compression"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Endianness"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Event"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Hello"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Item"
+	rs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/RecordStat"
+	si "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerInfo"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Stats"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Status"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
 )
@@ -11,62 +23,661 @@ const (
 	ErrContentIsTooLong = "content is too long"
 	ErrSizeIsTooShort   = "response size is too short: %v"
 	ErrSizeIsTooLong    = "response size is too long: %v"
+	ErrDataIsInvalid    = "response data is invalid"
+)
+
+const (
+	// RangeTotalSizeLen is the length of a total size of a data record which
+	// precedes a part of the record in a response.
+	RangeTotalSizeLen = 8
+
+	// ItemsCountLen is the length of a count of items in a response with many
+	// data records.
+	ItemsCountLen = 2
+
+	// ItemSizeLen is the length of a size of an item's data.
+	ItemSizeLen = 4
+
+	// CompressionLen is the length of a method of compression which precedes
+	// compressed data.
+	CompressionLen = 1
+
+	// ErrorCodeLen is the length of a code of a client's error which precedes
+	// the error message.
+	ErrorCodeLen = 2
+
+	// TokenSizeLen is the length of a size of a version token which precedes
+	// the data in a response with versioned data.
+	TokenSizeLen = 1
+
+	// EventTypeLen is the length of a type of event.
+	EventTypeLen = 1
+
+	// WarmUpCountLen is the length of a count of records in a result of a
+	// warm-up of cache.
+	WarmUpCountLen = 4
 )
 
 type Response struct {
-	Size   uint32 // Length of status name + data array.
+	Size   uint32 // Length of status name + request ID + data array.
 	Status status.Status
-	Data   []byte
+
+	// ID of the request to which this response is related.
+	RequestId uint32
+
+	Data []byte
+}
+
+// DataSize returns the size of the response's data according to the size of
+// the response.
+func (r *Response) DataSize() (dataSize uint) {
+	return uint(r.Size) - uint(protocol.StatusNameLen) - uint(protocol.RequestIdLen)
+}
+
+func New_ClientError(requestId uint32, code ec.ErrorCode, msg string) (resp *Response, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, ErrorCodeLen+len(msg))
+	data = bo.AppendUint16(data, uint16(code))
+	data = append(data, msg...)
+
+	return newNormalResponse(requestId, data, status.Status_ClientError)
+}
+
+// GetClientError returns the code and the message of a client's error. Old
+// servers send no details, such errors have an unknown code.
+func (r *Response) GetClientError() (code ec.ErrorCode, msg string, err error) {
+	if len(r.Data) == 0 {
+		return ec.ErrorCode_Unknown, "", nil
+	}
+
+	if len(r.Data) < ErrorCodeLen {
+		return ec.ErrorCode_None, "", errors.New(ErrDataIsInvalid)
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return ec.ErrorCode_None, "", err
+	}
+
+	return ec.ErrorCode(bo.Uint16(r.Data[0:ErrorCodeLen])), string(r.Data[ErrorCodeLen:]), nil
+}
+
+func New_OK(requestId uint32) (resp *Response, err error) {
+	return newSimpleResponse(requestId, status.Status_OK)
+}
+
+func New_Pong(requestId uint32) (resp *Response, err error) {
+	return newSimpleResponse(requestId, status.Status_Pong)
+}
+
+func New_ClosingConnection(requestId uint32) (resp *Response, err error) {
+	return newSimpleResponse(requestId, status.Status_ClosingConnection)
+}
+
+func New_RecordExists(requestId uint32) (resp *Response, err error) {
+	return newSimpleResponse(requestId, status.Status_RecordExists)
+}
+
+func New_RecordDoesNotExist(requestId uint32) (resp *Response, err error) {
+	return newSimpleResponse(requestId, status.Status_RecordDoesNotExist)
+}
+
+func New_FileExists(requestId uint32) (resp *Response, err error) {
+	return newSimpleResponse(requestId, status.Status_FileExists)
+}
+
+func New_FileDoesNotExist(requestId uint32) (resp *Response, err error) {
+	return newSimpleResponse(requestId, status.Status_FileDoesNotExist)
+}
+
+func New_Hello(requestId uint32, h *hello.Hello) (resp *Response, err error) {
+	var data []byte
+	data, err = h.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return newNormalResponse(requestId, data, status.Status_Hello)
+}
+
+// GetHello returns the hello message of a server.
+func (r *Response) GetHello() (h *hello.Hello, err error) {
+	return hello.NewFromBytes(r.Data)
+}
+
+func New_AuthChallenge(requestId uint32, challenge []byte) (resp *Response, err error) {
+	return newNormalResponse(requestId, challenge, status.Status_AuthChallenge)
+}
+
+// GetChallenge returns the challenge sent by server.
+func (r *Response) GetChallenge() (challenge []byte, err error) {
+	if len(r.Data) != auth.ChallengeLen {
+		return nil, errors.New(ErrDataIsInvalid)
+	}
+
+	return r.Data, nil
+}
+
+func New_ShowingData(requestId uint32, data []byte) (resp *Response, err error) {
+	return newNormalResponse(requestId, data, status.Status_ShowingData)
+}
+
+// New_ShowingCompressedData creates a response with compressed data. Data of
+// the response starts with the method of compression.
+func New_ShowingCompressedData(requestId uint32, c compression.Compression, data []byte) (resp *Response, err error) {
+	ba := make([]byte, 0, CompressionLen+len(data))
+	ba = append(ba, byte(c))
+	ba = append(ba, data...)
+
+	return newNormalResponse(requestId, ba, status.Status_ShowingCompressedData)
+}
+
+// GetCompressedData returns the method of compression and the compressed data.
+func (r *Response) GetCompressedData() (c compression.Compression, data []byte, err error) {
+	if len(r.Data) < CompressionLen {
+		return compression.Compression_None, nil, errors.New(ErrDataIsInvalid)
+	}
+
+	c, err = compression.NewFromByte(r.Data[0])
+	if err != nil {
+		return compression.Compression_None, nil, err
+	}
+
+	return c, r.Data[CompressionLen:], nil
+}
+
+// New_ShowingDataRange creates a response with a part of a data record. Data
+// of the response starts with the total size of the record.
+func New_ShowingDataRange(requestId uint32, totalSize uint64, data []byte) (resp *Response, err error) {
+	var ba []byte
+	ba, err = EncodeRangeTotalSize(totalSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return newNormalResponse(requestId, append(ba, data...), status.Status_ShowingDataRange)
+}
+
+// EncodeRangeTotalSize encodes the total size of a data record which precedes
+// a part of the record in a response.
+func EncodeRangeTotalSize(totalSize uint64) (ba []byte, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	ba = make([]byte, RangeTotalSizeLen)
+	bo.PutUint64(ba, totalSize)
+	return ba, nil
+}
+
+// GetDataRange reads data of a response with a part of a data record.
+func (r *Response) GetDataRange() (totalSize uint64, data []byte, err error) {
+	if len(r.Data) < RangeTotalSizeLen {
+		return 0, nil, errors.New(ErrDataIsInvalid)
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return bo.Uint64(r.Data[0:RangeTotalSizeLen]), r.Data[RangeTotalSizeLen:], nil
+}
+
+// New_NotModified creates a response which tells that the data record has
+// not been modified, i.e. its version token is the token sent by the client.
+func New_NotModified(requestId uint32) (resp *Response, err error) {
+	return newSimpleResponse(requestId, status.Status_NotModified)
+}
+
+// New_ShowingVersionedData creates a response with a data record and its
+// version token. The token goes first, preceded by its size.
+func New_ShowingVersionedData(requestId uint32, token []byte, data []byte) (resp *Response, err error) {
+	var ba []byte
+	ba, err = EncodeVersionToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return newNormalResponse(requestId, append(ba, data...), status.Status_ShowingVersionedData)
+}
+
+// EncodeVersionToken encodes the version token which precedes the data in a
+// response with versioned data.
+func EncodeVersionToken(token []byte) (ba []byte, err error) {
+	if len(token) > protocol.VersionTokenLenMax {
+		return nil, errors.New(ErrDataIsInvalid)
+	}
+
+	ba = make([]byte, 0, TokenSizeLen+len(token))
+	ba = append(ba, byte(len(token)))
+	ba = append(ba, token...)
+	return ba, nil
+}
+
+// GetVersionedData reads data of a response with a data record and its
+// version token.
+func (r *Response) GetVersionedData() (token []byte, data []byte, err error) {
+	if len(r.Data) < TokenSizeLen {
+		return nil, nil, errors.New(ErrDataIsInvalid)
+	}
+
+	tokenSize := int(r.Data[0])
+	if len(r.Data) < TokenSizeLen+tokenSize {
+		return nil, nil, errors.New(ErrDataIsInvalid)
+	}
+
+	return r.Data[TokenSizeLen : TokenSizeLen+tokenSize], r.Data[TokenSizeLen+tokenSize:], nil
+}
+
+// New_ShowingDataMany creates a response with many data records. Count of
+// items goes first, then each item is written as its status, size of its data
+// and its data. Data of an item with an error is written as in a response
+// with a client error, i.e. the code of the error followed by its text.
+func New_ShowingDataMany(requestId uint32, items []*item.Item) (resp *Response, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	data := bo.AppendUint16(nil, uint16(len(items)))
+
+	var ba []byte
+	for _, itm := range items {
+		ba, err = itm.Status.Bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(itm.Data) > protocol.ContentLenMax {
+			return nil, errors.New(ErrContentIsTooLong)
+		}
+
+		data = append(data, ba...)
+		if itm.IsError() {
+			data = bo.AppendUint32(data, uint32(ErrorCodeLen+len(itm.Data)))
+			data = bo.AppendUint16(data, uint16(itm.ErrorCode))
+		} else {
+			data = bo.AppendUint32(data, uint32(len(itm.Data)))
+		}
+		data = append(data, itm.Data...)
+	}
+
+	return newNormalResponse(requestId, data, status.Status_ShowingDataMany)
 }
 
-func New_ClientError() (resp *Response, err error) {
-	return newSimpleResponse(status.Status_ClientError)
+// GetItems reads data of a response with many data records.
+func (r *Response) GetItems() (items []*item.Item, err error) {
+	if len(r.Data) < ItemsCountLen {
+		return nil, errors.New(ErrDataIsInvalid)
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	count := int(bo.Uint16(r.Data[0:ItemsCountLen]))
+	items = make([]*item.Item, 0, count)
+	pos := ItemsCountLen
+
+	var itm *item.Item
+	var itemSize int
+	for i := 0; i < count; i++ {
+		if pos+protocol.StatusNameLen+ItemSizeLen > len(r.Data) {
+			return nil, errors.New(ErrDataIsInvalid)
+		}
+
+		itm = &item.Item{}
+		itm.Status, err = status.NewFromString(string(r.Data[pos : pos+protocol.StatusNameLen]))
+		if err != nil {
+			return nil, err
+		}
+		pos += protocol.StatusNameLen
+
+		itemSize = int(bo.Uint32(r.Data[pos : pos+ItemSizeLen]))
+		pos += ItemSizeLen
+		if (itemSize < 0) || (itemSize > len(r.Data)-pos) {
+			return nil, errors.New(ErrDataIsInvalid)
+		}
+
+		if itm.IsError() {
+			if itemSize < ErrorCodeLen {
+				return nil, errors.New(ErrDataIsInvalid)
+			}
+
+			itm.ErrorCode = ec.ErrorCode(bo.Uint16(r.Data[pos : pos+ErrorCodeLen]))
+			pos += ErrorCodeLen
+			itemSize -= ErrorCodeLen
+		}
+
+		if itemSize > 0 {
+			itm.Data = r.Data[pos : pos+itemSize]
+		}
+		pos += itemSize
+
+		items = append(items, itm)
+	}
+
+	if pos != len(r.Data) {
+		return nil, errors.New(ErrDataIsInvalid)
+	}
+
+	return items, nil
 }
 
-func New_OK() (resp *Response, err error) {
-	return newSimpleResponse(status.Status_OK)
+func New_ShowingRecordStat(requestId uint32, stat *rs.RecordStat) (resp *Response, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, rs.EncodedLen)
+	data = bo.AppendUint64(data, stat.Size)
+	data = bo.AppendUint64(data, uint64(stat.ModTime.UnixNano()))
+	data = append(data, stat.Hash[:]...)
+	if stat.IsCached {
+		data = append(data, 1)
+	} else {
+		data = append(data, 0)
+	}
+
+	return newNormalResponse(requestId, data, status.Status_ShowingRecordStat)
 }
 
-func New_ClosingConnection() (resp *Response, err error) {
-	return newSimpleResponse(status.Status_ClosingConnection)
+func (r *Response) GetRecordStat() (stat *rs.RecordStat, err error) {
+	if len(r.Data) != rs.EncodedLen {
+		return nil, errors.New(ErrDataIsInvalid)
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	stat = &rs.RecordStat{}
+	pos := 0
+
+	stat.Size = bo.Uint64(r.Data[pos : pos+rs.SizeLen])
+	pos += rs.SizeLen
+
+	stat.ModTime = time.Unix(0, int64(bo.Uint64(r.Data[pos:pos+rs.ModTimeLen])))
+	pos += rs.ModTimeLen
+
+	copy(stat.Hash[:], r.Data[pos:pos+rs.HashLen])
+	pos += rs.HashLen
+
+	switch r.Data[pos] {
+	case 0:
+		stat.IsCached = false
+	case 1:
+		stat.IsCached = true
+	default:
+		return nil, errors.New(ErrDataIsInvalid)
+	}
+
+	return stat, nil
 }
 
-func New_RecordExists() (resp *Response, err error) {
-	return newSimpleResponse(status.Status_RecordExists)
+// New_ShowingRecordList creates a response with a page of a list of records.
+// The next cursor is the last UID of the page when there are more records,
+// otherwise it is empty.
+func New_ShowingRecordList(requestId uint32, uids []string, nextCursor string) (resp *Response, err error) {
+	if len(uids) > protocol.RecordListLenMax {
+		return nil, errors.New(ErrContentIsTooLong)
+	}
+	if len(nextCursor) > protocol.UidLenMax {
+		return nil, errors.New(ErrContentIsTooLong)
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	data := []byte{byte(len(nextCursor))}
+	data = append(data, nextCursor...)
+	data = bo.AppendUint16(data, uint16(len(uids)))
+	for _, u := range uids {
+		if len(u) > protocol.UidLenMax {
+			return nil, errors.New(ErrContentIsTooLong)
+		}
+
+		data = append(data, byte(len(u)))
+		data = append(data, u...)
+	}
+
+	return newNormalResponse(requestId, data, status.Status_ShowingRecordList)
 }
 
-func New_RecordDoesNotExist() (resp *Response, err error) {
-	return newSimpleResponse(status.Status_RecordDoesNotExist)
+// GetRecordList reads data of a response with a page of a list of records.
+func (r *Response) GetRecordList() (uids []string, nextCursor string, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(r.Data) < protocol.UidSizeLen {
+		return nil, "", errors.New(ErrDataIsInvalid)
+	}
+
+	pos := protocol.UidSizeLen
+	cursorSize := int(r.Data[0])
+	if pos+cursorSize+ItemsCountLen > len(r.Data) {
+		return nil, "", errors.New(ErrDataIsInvalid)
+	}
+	nextCursor = string(r.Data[pos : pos+cursorSize])
+	pos += cursorSize
+
+	count := int(bo.Uint16(r.Data[pos : pos+ItemsCountLen]))
+	pos += ItemsCountLen
+	if count > protocol.RecordListLenMax {
+		return nil, "", errors.New(ErrDataIsInvalid)
+	}
+
+	uids = make([]string, 0, count)
+	var uidSize int
+	for i := 0; i < count; i++ {
+		if pos >= len(r.Data) {
+			return nil, "", errors.New(ErrDataIsInvalid)
+		}
+
+		uidSize = int(r.Data[pos])
+		pos++
+		if pos+uidSize > len(r.Data) {
+			return nil, "", errors.New(ErrDataIsInvalid)
+		}
+
+		uids = append(uids, string(r.Data[pos:pos+uidSize]))
+		pos += uidSize
+	}
+
+	if pos != len(r.Data) {
+		return nil, "", errors.New(ErrDataIsInvalid)
+	}
+
+	return uids, nextCursor, nil
+}
+
+// New_Event creates a message with an event. Events of a watch have the ID of
+// the request which has started the watch.
+func New_Event(requestId uint32, e *event.Event) (resp *Response, err error) {
+	if len(e.UID) > protocol.UidLenMax {
+		return nil, errors.New(ErrContentIsTooLong)
+	}
+
+	data := make([]byte, 0, EventTypeLen+protocol.UidSizeLen+len(e.UID))
+	data = append(data, byte(e.Type))
+	data = append(data, byte(len(e.UID)))
+	data = append(data, e.UID...)
+
+	return newNormalResponse(requestId, data, status.Status_Event)
+}
+
+// GetEvent reads data of a message with an event.
+func (r *Response) GetEvent() (e *event.Event, err error) {
+	if len(r.Data) < EventTypeLen+protocol.UidSizeLen {
+		return nil, errors.New(ErrDataIsInvalid)
+	}
+
+	e = &event.Event{}
+	e.Type, err = event.NewType(r.Data[0])
+	if err != nil {
+		return nil, err
+	}
+
+	uidSize := int(r.Data[EventTypeLen])
+	if len(r.Data) != EventTypeLen+protocol.UidSizeLen+uidSize {
+		return nil, errors.New(ErrDataIsInvalid)
+	}
+
+	e.UID = string(r.Data[EventTypeLen+protocol.UidSizeLen:])
+
+	return e, nil
+}
+
+// New_RecordsWarmed creates a response with a result of a warm-up of cache.
+// Loaded records are in cache, failed records are missing, unreadable or too
+// large to be cached.
+func New_RecordsWarmed(requestId uint32, loaded uint32, failed uint32) (resp *Response, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, WarmUpCountLen*2)
+	data = bo.AppendUint32(data, loaded)
+	data = bo.AppendUint32(data, failed)
+
+	return newNormalResponse(requestId, data, status.Status_RecordsWarmed)
+}
+
+// GetWarmUpResult reads data of a response with a result of a warm-up of
+// cache.
+func (r *Response) GetWarmUpResult() (loaded uint32, failed uint32, err error) {
+	if len(r.Data) != WarmUpCountLen*2 {
+		return 0, 0, errors.New(ErrDataIsInvalid)
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return bo.Uint32(r.Data[0:WarmUpCountLen]), bo.Uint32(r.Data[WarmUpCountLen:]), nil
+}
+
+// New_ShowingCacheStats creates a response with statistics of cache.
+func New_ShowingCacheStats(requestId uint32, cs *stats.CacheStats) (resp *Response, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, stats.CacheStatsEncodedLen)
+	data = bo.AppendUint64(data, cs.Hits)
+	data = bo.AppendUint64(data, cs.Misses)
+	data = bo.AppendUint64(data, cs.Entries)
+	data = bo.AppendUint64(data, cs.Volume)
+	data = bo.AppendUint64(data, cs.VolumeMax)
+	data = bo.AppendUint64(data, cs.Evictions)
+	data = bo.AppendUint64(data, cs.DiskReads)
+	data = bo.AppendUint64(data, cs.RejectedRecords)
+	data = bo.AppendUint64(data, cs.CompressedEntries)
+	data = bo.AppendUint64(data, cs.CompressedVolume)
+	data = bo.AppendUint64(data, cs.CompressedVolumeMax)
+
+	return newNormalResponse(requestId, data, status.Status_ShowingCacheStats)
 }
 
-func New_FileExists() (resp *Response, err error) {
-	return newSimpleResponse(status.Status_FileExists)
+// GetCacheStats reads data of a response with statistics of cache. Counters
+// which are not sent by older servers are zero.
+func (r *Response) GetCacheStats() (cs *stats.CacheStats, err error) {
+	if (len(r.Data) != stats.CacheStatsEncodedLen) && (len(r.Data) != stats.CacheStatsEncodedLenMin) {
+		return nil, errors.New(ErrDataIsInvalid)
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make([]uint64, stats.CacheStatsEncodedLen/stats.CounterLen)
+	for pos := 0; pos < len(r.Data); pos += stats.CounterLen {
+		counters[pos/stats.CounterLen] = bo.Uint64(r.Data[pos : pos+stats.CounterLen])
+	}
+
+	cs = &stats.CacheStats{
+		Hits:                counters[0],
+		Misses:              counters[1],
+		Entries:             counters[2],
+		Volume:              counters[3],
+		VolumeMax:           counters[4],
+		Evictions:           counters[5],
+		DiskReads:           counters[6],
+		RejectedRecords:     counters[7],
+		CompressedEntries:   counters[8],
+		CompressedVolume:    counters[9],
+		CompressedVolumeMax: counters[10],
+	}
+
+	return cs, nil
 }
 
-func New_FileDoesNotExist() (resp *Response, err error) {
-	return newSimpleResponse(status.Status_FileDoesNotExist)
+// New_ShowingServerInfo creates a response with information about the
+// server.
+func New_ShowingServerInfo(requestId uint32, info *si.ServerInfo) (resp *Response, err error) {
+	var data []byte
+	data, err = info.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return newNormalResponse(requestId, data, status.Status_ShowingServerInfo)
 }
 
-func New_ShowingData(data []byte) (resp *Response, err error) {
-	return newNormalResponse(data, status.Status_ShowingData)
+// GetServerInfo reads data of a response with information about the server.
+func (r *Response) GetServerInfo() (info *si.ServerInfo, err error) {
+	return si.NewFromBytes(r.Data)
 }
 
-func newSimpleResponse(status status.Status) (resp *Response, err error) {
+func newSimpleResponse(requestId uint32, status status.Status) (resp *Response, err error) {
 	return &Response{
-		Size:   protocol.StatusNameLen,
-		Status: status,
+		Size:      protocol.StatusNameLen + protocol.RequestIdLen,
+		Status:    status,
+		RequestId: requestId,
 	}, nil
 }
 
-func newNormalResponse(data []byte, status status.Status) (resp *Response, err error) {
+func newNormalResponse(requestId uint32, data []byte, status status.Status) (resp *Response, err error) {
 	if len(data) > protocol.ContentLenMax {
 		return nil, fmt.Errorf(ErrContentIsTooLong)
 	}
 
 	resp = &Response{
-		Size:   uint32(protocol.StatusNameLen + len(data)),
-		Status: status,
-		Data:   data,
+		Size:      uint32(protocol.StatusNameLen + protocol.RequestIdLen + len(data)),
+		Status:    status,
+		RequestId: requestId,
+		Data:      data,
 	}
 
 	return resp, nil
@@ -0,0 +1,57 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Item"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
+)
+
+func Test_ShowingDataMany_Items(t *testing.T) {
+	resp, err := New_ShowingDataMany(1, []*item.Item{
+		item.New_Found([]byte("1")),
+		item.New_NotFound(),
+		item.New_Error(ec.ErrorCode_ServerError, "failure"),
+		item.New_Error(ec.ErrorCode_PathIsNotValid, ""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := resp.GetItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 {
+		t.Fatalf("unexpected count of items: %v", len(items))
+	}
+
+	if !items[0].IsFound() || (string(items[0].Data) != "1") {
+		t.Fatalf("found item: %+v", items[0])
+	}
+	if !items[1].IsNotFound() || (len(items[1].Data) != 0) {
+		t.Fatalf("missing item: %+v", items[1])
+	}
+
+	// Errors keep their codes.
+	if !items[2].IsError() || (items[2].ErrorCode != ec.ErrorCode_ServerError) || (string(items[2].Data) != "failure") {
+		t.Fatalf("error item: %+v", items[2])
+	}
+	if !items[3].IsError() || (items[3].ErrorCode != ec.ErrorCode_PathIsNotValid) || (len(items[3].Data) != 0) {
+		t.Fatalf("error item without text: %+v", items[3])
+	}
+
+	// Data of the last error item is shorter than a code of an error.
+	bo, err := protocol.Endianness.ByteOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Data = resp.Data[:len(resp.Data)-1]
+	bo.PutUint32(resp.Data[len(resp.Data)-ItemSizeLen-1:], 1)
+	_, err = resp.GetItems()
+	if err == nil {
+		t.Fatal("short error item is accepted")
+	}
+}
@@ -1,28 +1,45 @@
 package server
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/vault-thirteen/Cache/VL"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Client"
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Connection"
-	ff "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/FilesFolder"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Hello"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Method"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Request"
+	si "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerInfo"
 	ss "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerSettings"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
-	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/tcp"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/socket"
+	ae "github.com/vault-thirteen/auxie/errors"
 )
 
 const (
 	ErrConnectionAccepting = "error accepting a connection: "
+	ErrRangeIsNotValid     = "range is not valid: offset %v, size %v"
+	ErrAuthIsNotEnabled    = "authentication is not enabled"
+	ErrAuthIsRequired      = "authentication is required"
+	ErrAuthFailed          = "authentication failed"
+	ErrItemIsNotAvailable  = "item is not available because of an error of server"
 	MsgResettingCache      = "Resetting the Cache ..."
 )
 
+// AcceptRetryInterval is a pause after an error of accepting a connection,
+// so that a persistent error does not make the loop spin.
+const AcceptRetryInterval = time.Millisecond * 100
+
 // Server is server.
 type Server struct {
 	settings *ss.ServerSettings
@@ -30,16 +47,59 @@ type Server struct {
 	mainDsn string
 	auxDsn  string
 
-	mainListener     *net.TCPListener
-	mainListenerAddr *net.TCPAddr
+	// Listeners are either TCP listeners or Unix socket listeners, unless
+	// they are given by options of an embedded server.
+	mainListener     net.Listener
+	mainListenerAddr net.Addr
+
+	auxListener     net.Listener
+	auxListenerAddr net.Addr
+
+	// TLS configuration of both listeners, nil when TLS is disabled.
+	tlsConfig *tls.Config
+
+	cache *vl.Cache[string, []byte] // UID is string, Data is a byte array with a version token.
+	files storage.Storage           // Data files.
+
+	// Statistics of the cache.
+	cacheMonitor *cacheMonitor
 
-	auxListener     *net.TCPListener
-	auxListenerAddr *net.TCPAddr
+	// Compressed copies of cached records and their statistics. Key is a
+	// name of a compression method followed by the version token of the
+	// record, so that a copy always belongs to the data it is made of. The
+	// cache is nil when compressed copies are not kept.
+	compressedCache   *vl.Cache[string, []byte]
+	compressedMonitor *cacheMonitor
 
-	cache *vl.Cache[string, []byte] // UID is string, Data is a byte array.
-	files *ff.FilesFolder           // Data files.
+	// Version tokens of records which are too large for cache.
+	streamTokens *streamTokens
+
+	// Clients watching the changes.
+	watchers     map[*watcher]bool
+	watchersLock *sync.Mutex
+
+	// Server is created with options rather than with a settings file.
+	isEmbedded bool
 
 	isRunning *atomic.Bool
+
+	// Open connections of clients. They are finished when the server stops.
+	connections     map[*connection.Connection]bool
+	connectionsLock *sync.Mutex
+
+	// Goroutines handling connections and background goroutines of the
+	// server. Server waits for them when it stops.
+	handlers *sync.WaitGroup
+	workers  *sync.WaitGroup
+
+	// Channel which is closed when the server stops.
+	stopSignal chan struct{}
+
+	// Information about the server.
+	buildInfo       si.BuildInfo
+	startTime       time.Time
+	mainConnections *atomic.Int32
+	auxConnections  *atomic.Int32
 }
 
 func New(stn *ss.ServerSettings) (srv *Server, err error) {
@@ -48,36 +108,80 @@ func New(stn *ss.ServerSettings) (srv *Server, err error) {
 		return nil, err
 	}
 
-	srv = &Server{
-		settings: stn,
-		mainDsn:  fmt.Sprintf("%s:%d", stn.Hostname, stn.MainPort),
-		auxDsn:   fmt.Sprintf("%s:%d", stn.Hostname, stn.AuxPort),
+	srv, err = newServer(stn)
+	if err != nil {
+		return nil, err
 	}
 
-	srv.mainListenerAddr, err = net.ResolveTCPAddr(protocol.LowLevelProtocol, srv.mainDsn)
+	srv.files, err = newStorage(stn.Data)
 	if err != nil {
 		return nil, err
 	}
 
-	srv.auxListenerAddr, err = net.ResolveTCPAddr(protocol.LowLevelProtocol, srv.auxDsn)
+	srv.mainDsn = socket.Dsn(stn.Hostname, stn.MainPort, stn.MainSocket)
+	srv.auxDsn = socket.Dsn(stn.Hostname, stn.AuxPort, stn.AuxSocket)
+
+	srv.mainListenerAddr, err = socket.ResolveAddr(stn.Hostname, stn.MainPort, stn.MainSocket)
 	if err != nil {
 		return nil, err
 	}
 
+	srv.auxListenerAddr, err = socket.ResolveAddr(stn.Hostname, stn.AuxPort, stn.AuxSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	return srv, nil
+}
+
+// newServer creates a server without its listeners and its storage.
+func newServer(stn *ss.ServerSettings) (srv *Server, err error) {
+	srv = &Server{
+		settings: stn,
+	}
+
+	if stn.Tls != nil {
+		srv.tlsConfig, err = stn.Tls.NewServerConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	srv.isRunning = new(atomic.Bool)
 	srv.isRunning.Store(false)
 
+	srv.connections = make(map[*connection.Connection]bool)
+	srv.connectionsLock = new(sync.Mutex)
+	srv.handlers = new(sync.WaitGroup)
+	srv.workers = new(sync.WaitGroup)
+	srv.stopSignal = make(chan struct{})
+
+	srv.mainConnections = new(atomic.Int32)
+	srv.auxConnections = new(atomic.Int32)
+
 	srv.cache = vl.NewCache[string, []byte](
 		0,
 		srv.settings.Data.CacheVolumeMax,
 		srv.settings.Data.CachedItemTTL,
 	)
 
-	srv.files, err = ff.New(srv.settings.Data.Folder)
-	if err != nil {
-		return nil, err
+	srv.cacheMonitor = newCacheMonitor()
+
+	if srv.settings.Data.CompressedCacheVolumeMax > 0 {
+		srv.compressedCache = vl.NewCache[string, []byte](
+			0,
+			srv.settings.Data.CompressedCacheVolumeMax,
+			srv.settings.Data.CachedItemTTL,
+		)
+
+		srv.compressedMonitor = newCacheMonitor()
 	}
 
+	srv.streamTokens = newStreamTokens()
+
+	srv.watchers = make(map[*watcher]bool)
+	srv.watchersLock = new(sync.Mutex)
+
 	return srv, nil
 }
 
@@ -91,106 +195,205 @@ func (srv *Server) GetAuxDsn() (dsn string) {
 	return srv.auxDsn
 }
 
+// IsRunning checks whether the server has been started and has not been
+// stopped yet.
+func (srv *Server) IsRunning() (isRunning bool) {
+	return srv.isRunning.Load()
+}
+
+// SetBuildInfo sets the description of the server's program which is shown
+// to clients requesting information about the server. It must be set before
+// the server is started.
+func (srv *Server) SetBuildInfo(bi si.BuildInfo) {
+	srv.buildInfo = bi
+}
+
+// getServerInfo collects information about the server.
+func (srv *Server) getServerInfo() (info *si.ServerInfo) {
+	return &si.ServerInfo{
+		Build:           srv.buildInfo,
+		StartTime:       srv.startTime,
+		Uptime:          time.Since(srv.startTime),
+		Settings:        srv.settings.Parameters(),
+		MainConnections: uint32(srv.mainConnections.Load()),
+		AuxConnections:  uint32(srv.auxConnections.Load()),
+	}
+}
+
+// newHello creates a hello message of the server for a port. Features of the
+// connection are announced only when they are used: TLS, and authentication
+// when the port requires it.
+func (srv *Server) newHello(isAuthRequired bool) (h *hello.Hello) {
+	h = hello.New(protocol.ContentLenMax + protocol.StatusNameLen + protocol.RequestIdLen)
+
+	h.Features &^= hello.Features_Connection
+	if srv.tlsConfig != nil {
+		h.Features |= hello.Feature_Tls
+	}
+	if isAuthRequired {
+		h.Features |= hello.Feature_Auth
+	}
+
+	return h
+}
+
 // Start starts the server.
 func (srv *Server) Start() (cerr *ce.CommonError) {
+	// Listeners given by options are ready already.
 	var err error
-	srv.mainListener, err = net.ListenTCP(protocol.LowLevelProtocol, srv.mainListenerAddr)
-	if err != nil {
-		return ce.NewServerError(err.Error(), 0, 0, client.ClientIdNone)
+	if srv.mainListener == nil {
+		srv.mainListener, err = socket.Listen(srv.mainListenerAddr)
+		if err != nil {
+			return ce.NewServerError(err.Error(), 0, 0, client.ClientIdNone)
+		}
 	}
 
-	srv.auxListener, err = net.ListenTCP(protocol.LowLevelProtocol, srv.auxListenerAddr)
-	if err != nil {
-		return ce.NewServerError(err.Error(), 0, 0, client.ClientIdNone)
+	if srv.auxListener == nil {
+		srv.auxListener, err = socket.Listen(srv.auxListenerAddr)
+		if err != nil {
+			return ce.NewServerError(ae.Combine(err, srv.mainListener.Close()).Error(), 0, 0, client.ClientIdNone)
+		}
 	}
 
+	srv.applyBoundAddresses()
+
+	srv.startTime = time.Now()
 	srv.isRunning.Store(true)
-	go srv.runMainLoop()
-	go srv.runAuxLoop()
+	srv.workers.Add(2)
+	go srv.runAcceptLoop(srv.mainListener, srv.handleMainConnection, "Main")
+	go srv.runAcceptLoop(srv.auxListener, srv.handleAuxConnection, "Auxiliary")
+
+	if srv.settings.ChangesScanIntervalSec > 0 {
+		srv.workers.Add(1)
+		go srv.runChangesScanner()
+	}
+
+	if len(srv.settings.WarmManifest) > 0 {
+		srv.workers.Add(1)
+		go srv.warmFromManifest()
+	}
 
 	return nil
 }
 
-func (srv *Server) runMainLoop() {
-	for {
-		if !srv.isRunning.Load() {
-			break
-		}
+// runAcceptLoop accepts connections of the listener until the server is
+// stopped. Each connection is handled in its own goroutine.
+func (srv *Server) runAcceptLoop(listener net.Listener, handler func(conn net.Conn), name string) {
+	defer srv.workers.Done()
 
-		conn, err := srv.mainListener.AcceptTCP()
+	for {
+		conn, err := listener.Accept()
 		if err != nil {
+			if !srv.isRunning.Load() || errors.Is(err, net.ErrClosed) {
+				break
+			}
+
 			log.Println(ErrConnectionAccepting, err.Error())
+			time.Sleep(AcceptRetryInterval)
 			continue
 		}
 
-		err = tcp.EnableKeepAlives(conn, protocol.TcpKeepAliveIsEnabled, protocol.TcpKeepAlivePeriodSec)
+		err = socket.EnableKeepAlives(conn)
 		if err != nil {
 			log.Println(err.Error())
 			closeErr := conn.Close()
 			if closeErr != nil {
-				log.Println(err.Error())
+				log.Println(closeErr.Error())
 			}
 			continue
 		}
 
-		go srv.handleMainConnection(conn)
+		srv.handlers.Add(1)
+		go func() {
+			defer srv.handlers.Done()
+			handler(conn)
+		}()
 	}
 
-	log.Println("Main loop has stopped.")
+	log.Println(name + " loop has stopped.")
 }
 
-func (srv *Server) runAuxLoop() {
-	for {
-		if !srv.isRunning.Load() {
-			break
-		}
+func (srv *Server) handleMainConnection(conn net.Conn) {
+	netConn, err := srv.secureConnection(conn)
+	if err != nil {
+		log.Println(err)
+		return
+	}
 
-		conn, err := srv.auxListener.AcceptTCP()
-		if err != nil {
-			log.Println(ErrConnectionAccepting, err.Error())
-			continue
-		}
+	srv.mainConnections.Add(1)
+	defer srv.mainConnections.Add(-1)
 
-		err = tcp.EnableKeepAlives(conn, protocol.TcpKeepAliveIsEnabled, protocol.TcpKeepAlivePeriodSec)
-		if err != nil {
-			log.Println(err.Error())
-			closeErr := conn.Close()
-			if closeErr != nil {
-				log.Println(err.Error())
-			}
-			continue
-		}
+	srv.serveConnection(connection.New(netConn, client.ClientIdIncoming), srv.routeMainRequest)
+}
 
-		go srv.handleAuxConnection(conn)
+func (srv *Server) handleAuxConnection(conn net.Conn) {
+	netConn, err := srv.secureConnection(conn)
+	if err != nil {
+		log.Println(err)
+		return
 	}
 
-	log.Println("Auxiliary loop has stopped.")
+	srv.auxConnections.Add(1)
+	defer srv.auxConnections.Add(-1)
+
+	srv.serveConnection(connection.New(netConn, client.ClientIdIncoming), srv.routeAuxRequest)
 }
 
-// Stop stops the server.
-func (srv *Server) Stop() (cerr *ce.CommonError) {
-	var err error
-	err = srv.mainListener.Close()
-	if err != nil {
-		return ce.NewServerError(err.Error(), 0, 0, client.ClientIdNone)
+// secureConnection performs the TLS handshake on an accepted connection when
+// TLS is enabled. The connection is closed when the handshake fails.
+func (srv *Server) secureConnection(conn net.Conn) (netConn net.Conn, err error) {
+	if srv.tlsConfig == nil {
+		return conn, nil
 	}
 
-	err = srv.auxListener.Close()
+	tlsConn := tls.Server(conn, srv.tlsConfig)
+
+	err = tlsConn.SetDeadline(time.Now().Add(time.Second * protocol.TlsHandshakeTimeoutSec))
+	if err == nil {
+		err = tlsConn.Handshake()
+	}
+	if err == nil {
+		err = tlsConn.SetDeadline(time.Time{})
+	}
 	if err != nil {
-		return ce.NewServerError(err.Error(), 0, 0, client.ClientIdNone)
+		return nil, ae.Combine(err, conn.Close())
 	}
 
-	srv.isRunning.Store(false)
-	// Main and Aux Loops will stop automatically.
-
-	return nil
+	return tlsConn, nil
 }
 
-func (srv *Server) handleMainConnection(conn *net.TCPConn) {
-	con := connection.New(conn, 0, client.ClientIdIncoming)
+// serveConnection reads requests from the client's connection and processes
+// them using the router. Requests are processed concurrently, so that the
+// client is able to pipeline its requests. Responses are sent as soon as they
+// are ready, each response carries the ID of its request. The number of
+// requests being processed is limited by the settings; when the limit is
+// reached, next requests are not read until some of them are finished.
+func (srv *Server) serveConnection(
+	con *connection.Connection,
+	router func(con *connection.Connection, req *request.Request) (cerr *ce.CommonError),
+) {
+	var closeRequestId uint32 = protocol.RequestIdNone
+	var inFlight = new(sync.WaitGroup)
+	var inFlightSlots = make(chan struct{}, srv.settings.GetRequestsInFlightMax())
+
+	if !srv.addConnection(con) {
+		// Server is stopping.
+		derr := srv.finaliseConnection(con, closeRequestId)
+		if derr != nil {
+			log.Println(derr)
+		}
+		return
+	}
+	defer srv.removeConnection(con)
 
 	defer func() {
-		derr := srv.finaliseConnection(con)
+		// Requests being processed and watchers must be finished before the
+		// connection is closed, so that nothing is sent after the closing
+		// response.
+		inFlight.Wait()
+		srv.removeWatchers(con)
+
+		derr := srv.finaliseConnection(con, closeRequestId)
 		if derr != nil {
 			log.Println(derr)
 		}
@@ -201,90 +404,142 @@ func (srv *Server) handleMainConnection(conn *net.TCPConn) {
 
 	for {
 		req, cerr = con.GetNextRequest()
+		if (cerr != nil) && (req != nil) && cerr.IsClientError() {
+			cerr = srv.respond_clientError(con, req.Id, cerr)
+			if cerr == nil {
+				continue
+			}
+		}
 		if cerr != nil {
-			log.Println(cerr)
+			// Reading is interrupted when the server stops.
+			if !con.IsBroken() && srv.isRunning.Load() {
+				log.Println(cerr)
+			}
 			break
 		}
 
 		if req.IsCloseConnection() {
+			closeRequestId = req.Id
 			break
 		}
 
-		switch req.Method {
-		case method.Method_ShowData:
-			cerr = srv.act_showData(con, req)
-		case method.Method_SearchRecord:
-			cerr = srv.act_searchRecord(con, req)
-		case method.Method_SearchFile:
-			cerr = srv.act_searchFile(con, req)
-		default:
-			cerr = ce.NewClientError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
-		}
-		if cerr != nil {
-			if cerr.IsServerError() {
-				break
-			} else {
-				cerr = srv.respond_clientError(con)
-				if cerr != nil {
-					break
-				}
-				continue
-			}
-		}
+		inFlightSlots <- struct{}{}
+		inFlight.Add(1)
+		go func(req *request.Request) {
+			defer func() {
+				<-inFlightSlots
+				inFlight.Done()
+			}()
+			srv.processRequest(con, req, router)
+		}(req)
 	}
 }
 
-func (srv *Server) handleAuxConnection(conn *net.TCPConn) {
-	con := connection.New(conn, 0, client.ClientIdIncoming)
+// processRequest processes a single request. Client's errors are reported to
+// the client. Server's errors break the connection.
+func (srv *Server) processRequest(
+	con *connection.Connection,
+	req *request.Request,
+	router func(con *connection.Connection, req *request.Request) (cerr *ce.CommonError),
+) {
+	cerr := router(con, req)
+	if cerr == nil {
+		return
+	}
 
-	defer func() {
-		derr := srv.finaliseConnection(con)
-		if derr != nil {
-			log.Println(derr)
+	if cerr.IsClientError() {
+		cerr = srv.respond_clientError(con, req.Id, cerr)
+		if cerr == nil {
+			return
 		}
-	}()
+	}
 
-	var req *request.Request
-	var cerr *ce.CommonError
+	log.Println(cerr)
+	cerr = con.Break()
+	if cerr != nil {
+		log.Println(cerr)
+	}
+}
 
-	for {
-		req, cerr = con.GetNextRequest()
-		if cerr != nil {
-			log.Println(cerr)
-			break
-		}
+func (srv *Server) routeMainRequest(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	switch req.Method {
+	case method.Method_Hello:
+		return srv.act_hello(con, req, false)
+	case method.Method_Ping:
+		return srv.act_ping(con, req)
+	case method.Method_ShowData:
+		return srv.act_showData(con, req)
+	case method.Method_ShowDataRange:
+		return srv.act_showDataRange(con, req)
+	case method.Method_ShowDataMany:
+		return srv.act_showDataMany(con, req)
+	case method.Method_StatRecord:
+		return srv.act_statRecord(con, req)
+	case method.Method_ShowDataIfModified:
+		return srv.act_showDataIfModified(con, req)
+	case method.Method_SearchRecord:
+		return srv.act_searchRecord(con, req)
+	case method.Method_SearchFile:
+		return srv.act_searchFile(con, req)
+	case method.Method_ListRecords:
+		return srv.act_listRecords(con, req)
+	case method.Method_Watch:
+		return srv.act_watch(con, req)
+	default:
+		return ce.NewClientErrorWithCode(ec.ErrorCode_MethodIsNotSupported, fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+}
 
-		if req.IsCloseConnection() {
-			break
-		}
+func (srv *Server) routeAuxRequest(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	// Methods which are available before authentication.
+	switch req.Method {
+	case method.Method_Hello:
+		return srv.act_hello(con, req, srv.isAuthRequired())
+	case method.Method_Ping:
+		return srv.act_ping(con, req)
+	case method.Method_AuthChallenge:
+		return srv.act_authChallenge(con, req)
+	case method.Method_AuthResponse:
+		return srv.act_authResponse(con, req)
+	}
 
-		switch req.Method {
-		case method.Method_ForgetRecord:
-			cerr = srv.act_forgetRecord(con, req)
-		case method.Method_ResetCache:
-			cerr = srv.act_resetCache(con, req)
-		default:
-			cerr = ce.NewClientError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
-		}
-		if cerr != nil {
-			if cerr.IsServerError() {
-				break
-			} else {
-				cerr = srv.respond_clientError(con)
-				if cerr != nil {
-					break
-				}
-				continue
-			}
-		}
+	if srv.isAuthRequired() && !con.IsAuthenticated() {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_AuthenticationIsRequired, ErrAuthIsRequired, req.Method, 0, con.ClientId())
+	}
+
+	switch req.Method {
+	case method.Method_ForgetRecord:
+		return srv.act_forgetRecord(con, req)
+	case method.Method_ResetCache:
+		return srv.act_resetCache(con, req)
+	case method.Method_WarmRecords:
+		return srv.act_warmRecords(con, req)
+	case method.Method_ShowCacheStats:
+		return srv.act_showCacheStats(con, req)
+	case method.Method_ShowServerInfo:
+		return srv.act_showServerInfo(con, req)
+	default:
+		return ce.NewClientErrorWithCode(ec.ErrorCode_MethodIsNotSupported, fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
 	}
 }
 
+// isAuthRequired tells whether clients of the auxiliary port must
+// authenticate themselves.
+func (srv *Server) isAuthRequired() bool {
+	return len(srv.settings.AuxSecret) > 0
+}
+
 // finaliseConnection is a method used by a Server to finalise the client's
 // connection. This method is used either when the client requested to stop the
-// communication or when an internal error happened on the server.
-func (srv *Server) finaliseConnection(con *connection.Connection) (cerr *ce.CommonError) {
-	cerr = srv.respond_closingConnection(con)
+// communication or when an internal error happened on the server. When the
+// connection is closed not by the client's request, the request ID is
+// 'protocol.RequestIdNone'.
+func (srv *Server) finaliseConnection(con *connection.Connection, requestId uint32) (cerr *ce.CommonError) {
+	if con.IsBroken() {
+		return nil
+	}
+
+	cerr = srv.respond_closingConnection(con, requestId)
 	if cerr != nil {
 		return cerr
 	}
@@ -1,30 +1,319 @@
 package server
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"log"
-	"path/filepath"
 
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Auth"
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Compression"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Connection"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Event"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Item"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Method"
+	rs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/RecordStat"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Request"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
 )
 
-// act_showData shows a data record.
+// act_hello exchanges hello messages with the client. Features supported by
+// both sides are enabled for the connection. Server always tells about itself,
+// even when versions are not compatible, so that the client is able to report
+// the reason. The client is told whether the port requires authentication.
+// Returns a detailed error.
+func (srv *Server) act_hello(con *connection.Connection, req *request.Request, isAuthRequired bool) (cerr *ce.CommonError) {
+	if req.Method != method.Method_Hello {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	clientHello, err := req.GetHello()
+	if err != nil {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ParametersAreNotValid, err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	serverHello := srv.newHello(isAuthRequired)
+	if serverHello.IsCompatibleWith(clientHello) {
+		con.SetFeatures(serverHello.Features & clientHello.Features)
+		con.SetResponseSizeMax(clientHello.ResponseSizeMax)
+	}
+
+	return srv.respond_hello(con, req.Id, serverHello)
+}
+
+// act_ping answers a ping, so that the client knows that the connection is
+// alive.
+// Returns a detailed error.
+func (srv *Server) act_ping(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_Ping {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	return srv.respond_pong(con, req.Id)
+}
+
+// act_authChallenge sends a random challenge to the client. The client must
+// answer it with an HMAC of the challenge using the shared secret.
+// Returns a detailed error.
+func (srv *Server) act_authChallenge(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_AuthChallenge {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	if !srv.isAuthRequired() {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_MethodIsNotSupported, ErrAuthIsNotEnabled, req.Method, 0, con.ClientId())
+	}
+
+	challenge, err := auth.NewChallenge()
+	if err != nil {
+		return ce.NewServerError(err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	con.SetChallenge(challenge)
+
+	return srv.respond_authChallenge(con, req.Id, challenge)
+}
+
+// act_authResponse checks the client's answer to the last challenge. Each
+// challenge may be answered only once.
+// Returns a detailed error.
+func (srv *Server) act_authResponse(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_AuthResponse {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	if !srv.isAuthRequired() {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_MethodIsNotSupported, ErrAuthIsNotEnabled, req.Method, 0, con.ClientId())
+	}
+
+	signature, err := req.GetSignature()
+	if err != nil {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ParametersAreNotValid, err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	challenge := con.TakeChallenge()
+	if (challenge == nil) || !auth.Verify(srv.settings.AuxSecret, challenge, signature) {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_AuthenticationFailed, ErrAuthFailed, req.Method, 0, con.ClientId())
+	}
+
+	con.SetAuthenticated()
+
+	return srv.respond_ok(con, req.Id)
+}
+
+// act_showData shows a data record. Records are compressed when compression
+// is enabled for the connection, except for large records which are streamed.
 // Returns a detailed error.
 func (srv *Server) act_showData(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
 	if req.Method != method.Method_ShowData {
 		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
 	}
 
-	var data []byte
-	data, cerr = srv.getData(req.UID.String(), con.ClientId())
+	var data, token []byte
+	var stream storage.File
+	var streamSize int64
+	data, token, stream, streamSize, cerr = srv.getDataOrStream(req.UID.String(), con.ClientId())
+	if cerr != nil {
+		return cerr
+	}
+
+	if stream == nil {
+		c := chooseCompression(con.Features())
+		if c == compression.Compression_None {
+			return srv.respond_showingData(con, req.Id, data)
+		}
+
+		data, cerr = srv.getCompressedData(data, token, c, con.ClientId())
+		if cerr != nil {
+			return cerr
+		}
+
+		return srv.respond_showingCompressedData(con, req.Id, c, data)
+	}
+
+	defer func() {
+		derr := stream.Close()
+		if derr != nil {
+			log.Println(derr)
+		}
+	}()
+
+	return srv.respond_showingDataStream(con, req.Id, stream, streamSize)
+}
+
+// act_showDataRange shows a part of a data record. Cached records are not
+// read from the file storage. Parts of records which are not cached are read
+// from files, but the records are not cached.
+// Returns a detailed error.
+func (srv *Server) act_showDataRange(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_ShowDataRange {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	offset, length, err := req.GetRangeParameters()
+	if err != nil {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ParametersAreNotValid, err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	var start, end uint64
+
+	// Try to find the data in cache.
+	data, _, ok := srv.getCachedRecord(req.UID.String())
+	if ok {
+		start, end, err = calculateRange(uint64(len(data)), offset, length)
+		if err != nil {
+			return ce.NewClientErrorWithCode(ec.ErrorCode_RangeIsNotValid, err.Error(), req.Method, 0, con.ClientId())
+		}
+
+		return srv.respond_showingDataRange(con, req.Id, uint64(len(data)), data[start:end])
+	}
+
+	// Try the file storage.
+	var f storage.File
+	var fileSize int64
+	f, fileSize, cerr = srv.openFile(req.UID.String(), con.ClientId())
+	if cerr != nil {
+		return cerr
+	}
+
+	defer func() {
+		derr := f.Close()
+		if derr != nil {
+			log.Println(derr)
+		}
+	}()
+
+	srv.cacheMonitor.diskReads.Add(1)
+
+	start, end, err = calculateRange(uint64(fileSize), offset, length)
+	if err != nil {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_RangeIsNotValid, err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	part := io.NewSectionReader(f, int64(start), int64(end-start))
+	if part.Size() > int64(srv.settings.Data.CachedItemVolumeMax) {
+		return srv.respond_showingDataRangeStream(con, req.Id, uint64(fileSize), part, part.Size())
+	}
+
+	data, err = io.ReadAll(part)
+	if err != nil {
+		return ce.NewServerError(err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	return srv.respond_showingDataRange(con, req.Id, uint64(fileSize), data)
+}
+
+// act_showDataIfModified shows a data record when its version token differs
+// from the token which the client already has. Otherwise, the record is
+// reported as not modified and its data is not sent.
+// Returns a detailed error.
+func (srv *Server) act_showDataIfModified(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_ShowDataIfModified {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	clientToken, err := req.GetVersionToken()
+	if err != nil {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ParametersAreNotValid, err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	var data, token []byte
+	var stream storage.File
+	var streamSize int64
+	data, token, stream, streamSize, cerr = srv.getDataOrStream(req.UID.String(), con.ClientId())
+	if cerr != nil {
+		return cerr
+	}
+
+	if stream == nil {
+		if bytes.Equal(token, clientToken) {
+			return srv.respond_notModified(con, req.Id)
+		}
+
+		return srv.respond_showingVersionedData(con, req.Id, token, data)
+	}
+
+	defer func() {
+		derr := stream.Close()
+		if derr != nil {
+			log.Println(derr)
+		}
+	}()
+
+	// Tokens of records which are too large for cache are kept separately.
+	token, cerr = srv.getStreamToken(req.UID.String(), stream, streamSize, con.ClientId())
 	if cerr != nil {
 		return cerr
 	}
 
-	return srv.respond_showingData(con, data)
+	if bytes.Equal(token, clientToken) {
+		return srv.respond_notModified(con, req.Id)
+	}
+
+	return srv.respond_showingVersionedDataStream(con, req.Id, token, io.NewSectionReader(stream, 0, streamSize), streamSize)
+}
+
+// act_showDataMany shows many data records. Each record has its own status,
+// so that a missing record does not fail the whole request. Records which are
+// too large for cache are streamed when they are requested on their own, so
+// they are not read here. Total volume of the records is limited, records
+// which do not fit are marked as too large as well.
+// Returns a detailed error.
+func (srv *Server) act_showDataMany(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_ShowDataMany {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	uids, err := req.GetUidList()
+	if err != nil {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ParametersAreNotValid, err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	items := make([]*item.Item, 0, len(uids))
+	var data []byte
+	var stream storage.File
+	var volume int
+	for _, u := range uids {
+		data, _, stream, _, cerr = srv.getDataOrStream(u.String(), con.ClientId())
+		if cerr == nil {
+			if stream != nil {
+				derr := stream.Close()
+				if derr != nil {
+					log.Println(derr)
+				}
+				items = append(items, item.New_TooLarge())
+				continue
+			}
+
+			if volume+len(data) > protocol.ManyDataVolumeMax {
+				items = append(items, item.New_TooLarge())
+				continue
+			}
+
+			volume += len(data)
+			items = append(items, item.New_Found(data))
+			continue
+		}
+
+		if cerr.GetCode() == ec.ErrorCode_FileDoesNotExist {
+			items = append(items, item.New_NotFound())
+		} else if cerr.IsServerError() {
+			// Details of a server error are not sent to the client.
+			log.Println(cerr)
+			items = append(items, item.New_Error(ec.ErrorCode_ServerError, ErrItemIsNotAvailable))
+		} else {
+			code := cerr.GetCode()
+			if code == ec.ErrorCode_None {
+				code = ec.ErrorCode_Unknown
+			}
+			items = append(items, item.New_Error(code, cerr.Error()))
+		}
+	}
+
+	return srv.respond_showingDataMany(con, req.Id, items)
 }
 
 // act_searchRecord checks existence of a record.
@@ -36,9 +325,9 @@ func (srv *Server) act_searchRecord(con *connection.Connection, req *request.Req
 
 	var recExists = srv.cache.RecordExists(req.UID.String())
 	if recExists {
-		return srv.respond_recordExists(con)
+		return srv.respond_recordExists(con, req.Id)
 	} else {
-		return srv.respond_recordDoesNotExist(con)
+		return srv.respond_recordDoesNotExist(con, req.Id)
 	}
 }
 
@@ -49,7 +338,7 @@ func (srv *Server) act_searchFile(con *connection.Connection, req *request.Reque
 		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
 	}
 
-	var relPath = filepath.Join(req.UID.String()+srv.settings.Data.FileExtension, "")
+	var relPath = srv.getRelPath(req.UID.String())
 
 	fileExists, err := srv.files.FileExists(relPath)
 	if err != nil {
@@ -57,10 +346,74 @@ func (srv *Server) act_searchFile(con *connection.Connection, req *request.Reque
 	}
 
 	if fileExists {
-		return srv.respond_fileExists(con)
+		return srv.respond_fileExists(con, req.Id)
 	} else {
-		return srv.respond_fileDoesNotExist(con)
+		return srv.respond_fileDoesNotExist(con, req.Id)
+	}
+}
+
+// act_statRecord shows metadata of a data record without its data.
+// Returns a detailed error.
+func (srv *Server) act_statRecord(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_StatRecord {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	var stat *rs.RecordStat
+	stat, cerr = srv.getRecordStat(req.UID.String(), con.ClientId())
+	if cerr != nil {
+		return cerr
+	}
+
+	return srv.respond_showingRecordStat(con, req.Id, stat)
+}
+
+// act_listRecords shows a page of a list of records.
+// Returns a detailed error.
+func (srv *Server) act_listRecords(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_ListRecords {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	cursor, limit, err := req.GetListParameters()
+	if err != nil {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ParametersAreNotValid, err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	var uids []string
+	var nextCursor string
+	uids, nextCursor, cerr = srv.listRecords(req.UID.String(), cursor.String(), limit, con.ClientId())
+	if cerr != nil {
+		return cerr
+	}
+
+	return srv.respond_showingRecordList(con, req.Id, uids, nextCursor)
+}
+
+// act_watch subscribes the client to events. Events are sent by a separate
+// goroutine until the connection is finished.
+// Returns a detailed error.
+func (srv *Server) act_watch(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_Watch {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	// Subscription is confirmed before any event is sent.
+	cerr = srv.respond_ok(con, req.Id)
+	if cerr != nil {
+		return cerr
 	}
+
+	// The watcher belongs to the connection's handler, which is still
+	// running, so the server waits for the watcher when it stops.
+	w := srv.addWatcher(con, req.Id)
+	srv.handlers.Add(1)
+	go func() {
+		defer srv.handlers.Done()
+		srv.runWatcher(w)
+	}()
+
+	return nil
 }
 
 // act_forgetRecord removes a record from cache.
@@ -70,9 +423,10 @@ func (srv *Server) act_forgetRecord(con *connection.Connection, req *request.Req
 		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
 	}
 
-	srv.cache.RemoveRecord(req.UID.String())
+	srv.uncacheRecord(req.UID.String())
+	srv.publishEvent(event.New_RecordForgotten(req.UID.String()))
 
-	return srv.respond_ok(con)
+	return srv.respond_ok(con, req.Id)
 }
 
 // act_resetCache removes all records from cache.
@@ -84,10 +438,65 @@ func (srv *Server) act_resetCache(con *connection.Connection, req *request.Reque
 
 	log.Println(MsgResettingCache)
 
-	err := srv.cache.Clear()
+	err := srv.clearCache()
 	if err != nil {
 		return ce.NewServerError(err.Error(), req.Method, 0, con.ClientId())
 	}
 
-	return srv.respond_ok(con)
+	srv.publishEvent(event.New_CacheReset())
+
+	return srv.respond_ok(con, req.Id)
+}
+
+// act_warmRecords loads records into cache. Either the listed records or the
+// records which UIDs start with the prefix are loaded. The response with the
+// counts of loaded and failed records is sent when the loading is finished;
+// other requests of the client are processed meanwhile.
+// Returns a detailed error.
+func (srv *Server) act_warmRecords(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_WarmRecords {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	list, err := req.GetWarmUpParameters()
+	if err != nil {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ParametersAreNotValid, err.Error(), req.Method, 0, con.ClientId())
+	}
+
+	var uids []string
+	if list == nil {
+		uids, cerr = srv.findRecords(req.UID.String(), con.ClientId())
+		if cerr != nil {
+			return cerr
+		}
+	} else {
+		uids = make([]string, 0, len(list))
+		for _, u := range list {
+			uids = append(uids, u.String())
+		}
+	}
+
+	loaded, failed := srv.warmRecords(uids, con.ClientId())
+
+	return srv.respond_recordsWarmed(con, req.Id, loaded, failed)
+}
+
+// act_showCacheStats shows statistics of the cache.
+// Returns a detailed error.
+func (srv *Server) act_showCacheStats(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_ShowCacheStats {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	return srv.respond_showingCacheStats(con, req.Id, srv.getCacheStats())
+}
+
+// act_showServerInfo shows information about the server.
+// Returns a detailed error.
+func (srv *Server) act_showServerInfo(con *connection.Connection, req *request.Request) (cerr *ce.CommonError) {
+	if req.Method != method.Method_ShowServerInfo {
+		return ce.NewServerError(fmt.Sprintf(method.ErrUnsupportedMethod, req.Method), req.Method, 0, con.ClientId())
+	}
+
+	return srv.respond_showingServerInfo(con, req.Id, srv.getServerInfo())
 }
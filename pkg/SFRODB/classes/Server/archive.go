@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"log"
+
+	as "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ArchiveStorage"
+	ds "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/DataSettings"
+	ff "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/FilesFolder"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+)
+
+const (
+	ErrStorageIsNotArchive = "storage is not an archive"
+	MsgSwitchingArchive    = "Switching the archive to %s ..."
+)
+
+// newStorage creates a storage of data files by the data settings. Files are
+// stored either in an archive or in a folder. Compressed files of an archive
+// which may be cached are unpacked into memory, larger ones are streamed.
+func newStorage(data *ds.DataSettings) (s storage.Storage, err error) {
+	if len(data.Archive) > 0 {
+		return as.New(data.Archive, int64(data.CachedItemVolumeMax))
+	}
+
+	return ff.New(data.Folder)
+}
+
+// reloader is a storage which files may be replaced as a whole, e.g. an
+// archive, see the 'ArchiveStorage.Reload' method.
+type reloader interface {
+	Reload() (isReloaded bool, err error)
+}
+
+// SwitchArchive puts another archive in place of the archive with data files.
+// The switch is atomic, each request sees either the old archive or the new
+// one. Records which files differ in the two archives are removed from cache
+// and watching clients are notified, as when changes are found by a scan.
+// It works only when data files are stored in an archive.
+func (srv *Server) SwitchArchive(archivePath string) (err error) {
+	arcs, ok := srv.files.(*as.ArchiveStorage)
+	if !ok {
+		return errors.New(ErrStorageIsNotArchive)
+	}
+
+	log.Printf(MsgSwitchingArchive+"\r\n", archivePath)
+
+	var oldStates, newStates map[string]storage.FileState
+	oldStates, err = arcs.ScanFiles(srv.settings.Data.FileExtension)
+	if err != nil {
+		return err
+	}
+
+	err = arcs.Switch(archivePath)
+	if err != nil {
+		return err
+	}
+
+	newStates, err = arcs.ScanFiles(srv.settings.Data.FileExtension)
+	if err != nil {
+		return err
+	}
+
+	srv.processChanges(oldStates, newStates)
+	return nil
+}
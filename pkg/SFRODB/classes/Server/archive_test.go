@@ -0,0 +1,117 @@
+package server_test
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	as "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ArchiveStorage"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Event"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Server"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/sfrodbtest"
+)
+
+// writeZip writes a ZIP archive with the records, which are data by UID.
+func writeZip(tb testing.TB, filePath string, records map[string]string) {
+	tb.Helper()
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	zw := zip.NewWriter(f)
+	for uid, data := range records {
+		var w io.Writer
+		w, err = zw.Create(uid + sfrodbtest.FileExtensionDefault)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		_, err = w.Write([]byte(data))
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func Test_SwitchArchive(t *testing.T) {
+	dir := t.TempDir()
+	v1, v2 := filepath.Join(dir, "v1.zip"), filepath.Join(dir, "v2.zip")
+	writeZip(t, v1, map[string]string{"a": "1", "b": "3"})
+	writeZip(t, v2, map[string]string{"a": "2", "b": "3"})
+
+	arcs, err := as.New(v1, sfrodbtest.CachedItemVolumeMaxDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := sfrodbtest.NewServerWithOptions(t, nil, server.Options{Storage: arcs})
+	cli := ts.NewClient()
+
+	for uid, expected := range map[string]string{"a": "1", "b": "3"} {
+		data, cerr := cli.ShowData(uid)
+		if (cerr != nil) || (string(data) != expected) {
+			t.Fatalf("record %s: %q, %v", uid, data, cerr)
+		}
+	}
+
+	events, cerr := cli.Watch(context.Background())
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	err = ts.Server.SwitchArchive(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the changed record is reported.
+	select {
+	case e := <-events:
+		if (e.Type != event.Type_RecordChanged) || (e.UID != "a") {
+			t.Fatalf("unexpected event: %v", e)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("changed record is not reported")
+	}
+
+	data, cerr := cli.ShowData("a")
+	if (cerr != nil) || (string(data) != "2") {
+		t.Fatalf("new version: %q, %v", data, cerr)
+	}
+
+	// A failed switch keeps the current archive.
+	err = ts.Server.SwitchArchive(filepath.Join(dir, "missing.zip"))
+	if err == nil {
+		t.Fatal("missing archive is used")
+	}
+
+	data, cerr = cli.ShowData("a")
+	if (cerr != nil) || (string(data) != "2") {
+		t.Fatalf("after a failed switch: %q, %v", data, cerr)
+	}
+}
+
+func Test_SwitchArchive_Folder(t *testing.T) {
+	ts := sfrodbtest.NewServer(t, nil)
+
+	err := ts.Server.SwitchArchive(filepath.Join(t.TempDir(), "a.zip"))
+	if err == nil {
+		t.Fatal("folder is switched to an archive")
+	}
+}
@@ -0,0 +1,67 @@
+package server_test
+
+import (
+	"testing"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Auth"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Client"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Server"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/sfrodbtest"
+)
+
+func Test_Auth(t *testing.T) {
+	ts := sfrodbtest.NewServerWithOptions(t, map[string][]byte{"a": []byte("1")}, server.Options{
+		AuxSecret: auth.Secret("0123456789abcdef-secret"),
+	})
+
+	// A client with the secret uses the auxiliary methods.
+	cli := ts.NewClient()
+	if !cli.IsAuthRequired() {
+		t.Fatal("authentication is not announced")
+	}
+
+	cerr := cli.ResetCache()
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	// A client without a secret uses only the main methods.
+	stn := ts.ClientSettings()
+	stn.AuxSecret = nil
+	anon, err := client.New(stn, "no-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cerr = anon.Start()
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer func() {
+		_ = anon.Stop()
+	}()
+
+	cerr = anon.ResetCache()
+	if (cerr == nil) || (cerr.GetCode() != ec.ErrorCode_AuthenticationIsRequired) {
+		t.Fatalf("unexpected error: %v", cerr)
+	}
+
+	data, cerr := anon.ShowData("a")
+	if (cerr != nil) || (string(data) != "1") {
+		t.Fatalf("unexpected data: %q, %v", data, cerr)
+	}
+
+	// A client with a wrong secret is not started.
+	stn = ts.ClientSettings()
+	stn.AuxSecret = auth.Secret("0123456789abcdef-wrong")
+	wrong, err := client.New(stn, "wrong-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cerr = wrong.Start()
+	if (cerr == nil) || (cerr.GetCode() != ec.ErrorCode_AuthenticationFailed) {
+		t.Fatalf("unexpected error: %v", cerr)
+	}
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Compression"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Stats"
+)
+
+// cacheMonitor collects statistics of the cache. The cache does not report
+// its evictions, so the monitor keeps sizes of the records which have been
+// put into the cache. A record which is missing from the cache while it is
+// known to the monitor has been evicted. Such records are found when they are
+// missed and when the statistics are requested.
+type cacheMonitor struct {
+	hits            *atomic.Uint64
+	misses          *atomic.Uint64
+	evictions       *atomic.Uint64
+	diskReads       *atomic.Uint64
+	rejectedRecords *atomic.Uint64
+
+	// Sizes of cached records by UID and their total volume.
+	sizes     map[string]int
+	volume    int
+	sizesLock *sync.Mutex
+}
+
+func newCacheMonitor() (cm *cacheMonitor) {
+	return &cacheMonitor{
+		hits:            new(atomic.Uint64),
+		misses:          new(atomic.Uint64),
+		evictions:       new(atomic.Uint64),
+		diskReads:       new(atomic.Uint64),
+		rejectedRecords: new(atomic.Uint64),
+		sizes:           make(map[string]int),
+		sizesLock:       new(sync.Mutex),
+	}
+}
+
+// countMiss counts a request for a record which is not in the cache.
+func (cm *cacheMonitor) countMiss(uid string) {
+	cm.misses.Add(1)
+
+	cm.sizesLock.Lock()
+	defer cm.sizesLock.Unlock()
+
+	if cm.forget(uid) {
+		cm.evictions.Add(1)
+	}
+}
+
+// add registers a record which has been put into the cache.
+func (cm *cacheMonitor) add(uid string, size int) {
+	cm.sizesLock.Lock()
+	defer cm.sizesLock.Unlock()
+
+	cm.forget(uid)
+	cm.sizes[uid] = size
+	cm.volume += size
+}
+
+// remove unregisters a record which has been removed from the cache.
+func (cm *cacheMonitor) remove(uid string) {
+	cm.sizesLock.Lock()
+	defer cm.sizesLock.Unlock()
+
+	cm.forget(uid)
+}
+
+// clear unregisters all the records.
+func (cm *cacheMonitor) clear() {
+	cm.sizesLock.Lock()
+	defer cm.sizesLock.Unlock()
+
+	clear(cm.sizes)
+	cm.volume = 0
+}
+
+// forget unregisters a record. The caller must hold the lock.
+func (cm *cacheMonitor) forget(uid string) (isKnown bool) {
+	var size int
+	size, isKnown = cm.sizes[uid]
+	if isKnown {
+		delete(cm.sizes, uid)
+		cm.volume -= size
+	}
+
+	return isKnown
+}
+
+// getStats returns the statistics. Records which are not in the cache any
+// more are counted as evicted before the statistics are taken.
+func (cm *cacheMonitor) getStats(isCached func(uid string) bool, volumeMax int) (cs *stats.CacheStats) {
+	cm.sizesLock.Lock()
+	defer cm.sizesLock.Unlock()
+
+	for uid := range cm.sizes {
+		if !isCached(uid) {
+			cm.forget(uid)
+			cm.evictions.Add(1)
+		}
+	}
+
+	return &stats.CacheStats{
+		Hits:            cm.hits.Load(),
+		Misses:          cm.misses.Load(),
+		Entries:         uint64(len(cm.sizes)),
+		Volume:          uint64(cm.volume),
+		VolumeMax:       uint64(volumeMax),
+		Evictions:       cm.evictions.Load(),
+		DiskReads:       cm.diskReads.Load(),
+		RejectedRecords: cm.rejectedRecords.Load(),
+	}
+}
+
+// VersionTokenLen is the length of a version token of a record. A version
+// token is a SHA-256 hash sum of the record's data.
+const VersionTokenLen = sha256.Size
+
+// Records are kept in the cache together with their version tokens, so that a
+// token always belongs to the data it is stored with. An entry of the cache
+// is the token followed by the data.
+
+// newCacheEntry creates an entry of the cache for the record's data.
+func newCacheEntry(data []byte) (entry []byte) {
+	token := sha256.Sum256(data)
+
+	entry = make([]byte, 0, VersionTokenLen+len(data))
+	entry = append(entry, token[:]...)
+	return append(entry, data...)
+}
+
+// splitCacheEntry returns the data and the version token of a cached record.
+func splitCacheEntry(entry []byte) (data []byte, token []byte) {
+	return entry[VersionTokenLen:], entry[:VersionTokenLen:VersionTokenLen]
+}
+
+// getCachedRecord looks for a record in the cache. The request is counted as
+// a hit or as a miss.
+func (srv *Server) getCachedRecord(uid string) (data []byte, token []byte, ok bool) {
+	data, token, ok = srv.lookupCachedRecord(uid)
+	if !ok {
+		srv.cacheMonitor.countMiss(uid)
+		return nil, nil, false
+	}
+
+	srv.cacheMonitor.hits.Add(1)
+	return data, token, true
+}
+
+// lookupCachedRecord looks for a record in the cache. The request is not
+// counted in the statistics.
+func (srv *Server) lookupCachedRecord(uid string) (data []byte, token []byte, ok bool) {
+	entry, err := srv.cache.GetRecord(uid)
+	if (err != nil) || (len(entry) < VersionTokenLen) {
+		return nil, nil, false
+	}
+
+	data, token = splitCacheEntry(entry)
+	return data, token, true
+}
+
+// cacheRecord puts a record into the cache and returns its version token.
+func (srv *Server) cacheRecord(uid string, data []byte) (token []byte, err error) {
+	entry := newCacheEntry(data)
+
+	err = srv.cache.AddRecord(uid, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	srv.cacheMonitor.add(uid, len(entry))
+
+	_, token = splitCacheEntry(entry)
+	return token, nil
+}
+
+// uncacheRecord removes a record from the cache together with its compressed
+// copies.
+func (srv *Server) uncacheRecord(uid string) {
+	_, token, ok := srv.lookupCachedRecord(uid)
+
+	srv.cache.RemoveRecord(uid)
+	srv.cacheMonitor.remove(uid)
+
+	if ok && (srv.compressedCache != nil) {
+		for _, c := range []compression.Compression{compression.Compression_Gzip, compression.Compression_Deflate} {
+			key := compressedCacheKey(token, c)
+			srv.compressedCache.RemoveRecord(key)
+			srv.compressedMonitor.remove(key)
+		}
+	}
+}
+
+// clearCache removes all the records and their compressed copies from the
+// cache.
+func (srv *Server) clearCache() (err error) {
+	err = srv.cache.Clear()
+	if err != nil {
+		return err
+	}
+	srv.cacheMonitor.clear()
+
+	if srv.compressedCache != nil {
+		err = srv.compressedCache.Clear()
+		if err != nil {
+			return err
+		}
+		srv.compressedMonitor.clear()
+	}
+
+	return nil
+}
+
+// getCacheStats returns statistics of the cache, including the cache of
+// compressed copies.
+func (srv *Server) getCacheStats() (cs *stats.CacheStats) {
+	cs = srv.cacheMonitor.getStats(srv.cache.RecordExists, srv.settings.Data.CacheVolumeMax)
+
+	if srv.compressedCache != nil {
+		ccs := srv.compressedMonitor.getStats(srv.compressedCache.RecordExists, srv.settings.Data.CompressedCacheVolumeMax)
+		cs.CompressedEntries = ccs.Entries
+		cs.CompressedVolume = ccs.Volume
+		cs.CompressedVolumeMax = ccs.VolumeMax
+	}
+
+	return cs
+}
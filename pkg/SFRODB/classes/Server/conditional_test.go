@@ -0,0 +1,96 @@
+package server_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/sfrodbtest"
+)
+
+func Test_ShowDataIfModified(t *testing.T) {
+	ts := sfrodbtest.NewServer(t, map[string][]byte{"a": []byte("1")})
+	cli := ts.NewClient()
+
+	// A client without a token receives the data.
+	data, token, isModified, cerr := cli.ShowDataIfModified("a", nil)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !isModified || (string(data) != "1") || (len(token) == 0) {
+		t.Fatalf("first read: %q, %x, %v", data, token, isModified)
+	}
+
+	data, newToken, isModified, cerr := cli.ShowDataIfModified("a", token)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if isModified || (len(data) != 0) || !bytes.Equal(newToken, token) {
+		t.Fatalf("unchanged record: %q, %x, %v", data, newToken, isModified)
+	}
+
+	// A token does not depend on the cache.
+	cerr = cli.ResetCache()
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	_, _, isModified, cerr = cli.ShowDataIfModified("a", token)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if isModified {
+		t.Fatal("record is modified after a reset of the cache")
+	}
+
+	// A new version of the record has a new token.
+	ts.PutRecord("a", []byte("2"))
+	cerr = cli.ForgetRecord("a")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	data, newToken, isModified, cerr = cli.ShowDataIfModified("a", token)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !isModified || (string(data) != "2") || bytes.Equal(newToken, token) {
+		t.Fatalf("changed record: %q, %x, %v", data, newToken, isModified)
+	}
+}
+
+func Test_ShowDataIfModified_Stream(t *testing.T) {
+	// The record is too large for cache, so it is streamed.
+	big := bytes.Repeat([]byte("0123456789"), sfrodbtest.CachedItemVolumeMaxDefault/5)
+	ts := sfrodbtest.NewServer(t, map[string][]byte{"big": big})
+	cli := ts.NewClient()
+
+	data, token, isModified, cerr := cli.ShowDataIfModified("big", nil)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !isModified || !bytes.Equal(data, big) {
+		t.Fatalf("first read: %d bytes, %v", len(data), isModified)
+	}
+
+	for range 3 {
+		_, newToken, isModified, cerr := cli.ShowDataIfModified("big", token)
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+		if isModified || !bytes.Equal(newToken, token) {
+			t.Fatalf("unchanged record: %x, %v", newToken, isModified)
+		}
+	}
+
+	// A new version of the file has another size.
+	big = append(big, '!')
+	ts.PutRecord("big", big)
+
+	data, newToken, isModified, cerr := cli.ShowDataIfModified("big", token)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !isModified || !bytes.Equal(data, big) || bytes.Equal(newToken, token) {
+		t.Fatalf("changed record: %d bytes, %x, %v", len(data), newToken, isModified)
+	}
+}
@@ -1,39 +1,340 @@
 package server
 
 import (
-	"path/filepath"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"sort"
+	"strings"
 
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Compression"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Hello"
+	rs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/RecordStat"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+	uid "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/UID"
+	ae "github.com/vault-thirteen/auxie/errors"
 )
 
 // getData gets the data either from cache or from file storage.
 // Returns a detailed error.
 func (srv *Server) getData(uid string, clientId string) (data []byte, cerr *ce.CommonError) {
-	// Try to find the data in cache.
-	var err error
-	data, err = srv.cache.GetRecord(uid)
-	if err == nil {
+	var stream storage.File
+	data, _, stream, _, cerr = srv.getDataOrStream(uid, clientId)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if stream == nil {
 		return data, nil
 	}
 
+	// The item is too large for cache, but the caller needs all its data.
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		err = ae.Combine(err, stream.Close())
+		return nil, ce.NewServerError(err.Error(), 0, 0, clientId)
+	}
+
+	err = stream.Close()
+	if err != nil {
+		return nil, ce.NewServerError(err.Error(), 0, 0, clientId)
+	}
+
+	return data, nil
+}
+
+// getDataOrStream gets the data either from cache or from file storage. The
+// version token is returned with the data, it always belongs to this data.
+// Items which are larger than the maximum volume of a cached item are neither
+// read into memory nor cached. An opened file is returned for such an item
+// instead, so that its contents could be streamed. The caller must close the
+// file.
+// Returns a detailed error.
+func (srv *Server) getDataOrStream(uid string, clientId string) (data []byte, token []byte, stream storage.File, streamSize int64, cerr *ce.CommonError) {
+	// Try to find the data in cache.
+	var ok bool
+	data, token, ok = srv.getCachedRecord(uid)
+	if ok {
+		return data, token, nil, 0, nil
+	}
+
 	// Try the file storage.
-	// Add an extension and convert path to the style of a current OS.
-	relPath := filepath.Join(uid+srv.settings.Data.FileExtension, "")
-	var fileExists bool
-	fileExists, data, err = srv.files.GetFileContents(relPath)
+	return srv.readRecord(uid, clientId)
+}
+
+// readRecord reads a record from the file storage and puts it into cache.
+// Cache is not looked up, so the request is not counted as a cache miss.
+// Items which are too large for cache are returned as opened files, see the
+// 'getDataOrStream' method.
+// Returns a detailed error.
+func (srv *Server) readRecord(uid string, clientId string) (data []byte, token []byte, stream storage.File, streamSize int64, cerr *ce.CommonError) {
+	var f storage.File
+	var fileSize int64
+	f, fileSize, cerr = srv.openFile(uid, clientId)
+	if cerr != nil {
+		return nil, nil, nil, 0, cerr
+	}
+
+	srv.cacheMonitor.diskReads.Add(1)
+
+	if fileSize > int64(srv.settings.Data.CachedItemVolumeMax) {
+		srv.cacheMonitor.rejectedRecords.Add(1)
+		return nil, nil, f, fileSize, nil
+	}
+
+	data, err := io.ReadAll(f)
+	err = ae.Combine(err, f.Close())
+	if err != nil {
+		return nil, nil, nil, 0, ce.NewServerError(err.Error(), 0, 0, clientId)
+	}
+
+	// Save data in the cache.
+	token, err = srv.cacheRecord(uid, data)
+	if err != nil {
+		return nil, nil, nil, 0, ce.NewServerError(err.Error(), 0, 0, clientId)
+	}
+
+	return data, token, nil, 0, nil
+}
+
+// getRelPath returns the relative path of a file of a data record in the
+// storage.
+func (srv *Server) getRelPath(uid string) (relPath string) {
+	return path.Clean(uid + srv.settings.Data.FileExtension)
+}
+
+// openFile opens a file of a data record. The caller must close the file.
+// Returns a detailed error.
+func (srv *Server) openFile(uid string, clientId string) (f storage.File, fileSize int64, cerr *ce.CommonError) {
+	relPath := srv.getRelPath(uid)
+
+	fileExists, f, fileSize, err := srv.files.OpenFile(relPath)
+	if !fileExists {
+		return nil, 0, missingFileError(relPath, err, clientId)
+	}
+	if err != nil {
+		return nil, 0, ce.NewServerError(err.Error(), 0, 0, clientId)
+	}
+
+	return f, fileSize, nil
+}
+
+// statFile gets the state of a file of a data record.
+// Returns a detailed error.
+func (srv *Server) statFile(uid string, clientId string) (state storage.FileState, cerr *ce.CommonError) {
+	relPath := srv.getRelPath(uid)
+
+	fileExists, state, err := srv.files.StatFile(relPath)
 	if !fileExists {
-		// When file is not found, we count it as client's error.
-		return nil, ce.NewClientError(err.Error(), 0, 0, clientId)
+		return state, missingFileError(relPath, err, clientId)
+	}
+	if err != nil {
+		return state, ce.NewServerError(err.Error(), 0, 0, clientId)
+	}
+
+	return state, nil
+}
+
+// missingFileError creates an error of a file which is not found in the
+// storage. When file is not found, we count it as client's error. Path of the
+// file is not shown to the client.
+func missingFileError(relPath string, err error, clientId string) (cerr *ce.CommonError) {
+	if (err != nil) && (err.Error() == storage.ErrRelPathIsNotValid) {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_PathIsNotValid, err.Error(), 0, 0, clientId)
+	}
+
+	return ce.NewClientErrorWithCode(ec.ErrorCode_FileDoesNotExist, fmt.Sprintf(storage.ErrFileDoesNotExist, relPath), 0, 0, clientId)
+}
+
+// listRecords gets a page of a sorted list of records which UIDs start with
+// the prefix. The page starts after the cursor. When there are more records,
+// the next cursor is the last UID of the page, otherwise it is empty. Files
+// which names are not valid UIDs are skipped. Records are sorted by their
+// UIDs rather than by names of their files, which have the extension. The
+// listing is not kept between requests, so the whole storage is listed for
+// each page, i.e. a page costs O(N) for N files.
+// Returns a detailed error.
+func (srv *Server) listRecords(prefix string, cursor string, limit int, clientId string) (uids []string, nextCursor string, cerr *ce.CommonError) {
+	relPaths, err := srv.files.ListFiles(srv.settings.Data.FileExtension)
+	if err != nil {
+		return nil, "", ce.NewServerError(err.Error(), 0, 0, clientId)
+	}
+
+	all := make([]string, 0, len(relPaths))
+	var u string
+	var ok bool
+	for _, relPath := range relPaths {
+		u, ok = srv.getUidOfFile(relPath)
+		if !ok || !strings.HasPrefix(u, prefix) {
+			continue
+		}
+
+		all = append(all, u)
+	}
+	sort.Strings(all)
+
+	// The page starts with the first UID after the cursor.
+	start := sort.Search(len(all), func(i int) bool { return all[i] > cursor })
+	end := min(start+limit, len(all))
+
+	uids = all[start:end]
+	if end < len(all) {
+		return uids, uids[len(uids)-1], nil
+	}
+
+	return uids, "", nil
+}
+
+// getUidOfFile returns the UID of a record stored in the file. Files which
+// names are not valid UIDs can not be requested, so they are not records.
+func (srv *Server) getUidOfFile(relPath string) (u string, ok bool) {
+	u = strings.TrimSuffix(relPath, srv.settings.Data.FileExtension)
+
+	// UIDs are trimmed, so names with spaces around are not reachable.
+	v, err := uid.New(u)
+	if (err != nil) || (v.String() != u) {
+		return "", false
 	}
+
+	return u, true
+}
+
+// getRecordStat gets metadata of a data record. Size and modification time are
+// taken from the file. Hash sum is the version token of the cached record when
+// the cached record has the size of the file, otherwise the token is taken
+// from the file, see 'getStreamToken'.
+// Returns a detailed error.
+func (srv *Server) getRecordStat(uid string, clientId string) (stat *rs.RecordStat, cerr *ce.CommonError) {
+	state, cerr := srv.statFile(uid, clientId)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	stat = &rs.RecordStat{
+		Size:    uint64(state.Size),
+		ModTime: state.ModTime,
+	}
+
+	data, token, ok := srv.lookupCachedRecord(uid)
+	stat.IsCached = ok
+	if ok && (int64(len(data)) == state.Size) {
+		copy(stat.Hash[:], token)
+		return stat, nil
+	}
+
+	// Cached record is outdated or it is not cached.
+	f, fileSize, cerr := srv.openFile(uid, clientId)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	defer func() {
+		derr := f.Close()
+		if derr != nil {
+			log.Println(derr)
+		}
+	}()
+
+	srv.cacheMonitor.diskReads.Add(1)
+
+	token, cerr = srv.getStreamToken(uid, f, fileSize, clientId)
+	if cerr != nil {
+		return nil, cerr
+	}
+	stat.Size = uint64(fileSize)
+	copy(stat.Hash[:], token)
+
+	return stat, nil
+}
+
+// getCompressedData returns a compressed copy of a cached record. The copy
+// is made only once and is kept in a separate cache, when it is enabled. The
+// copy is found by the version token of the record, so a copy of old data is
+// never used for new data.
+// Returns a detailed error.
+func (srv *Server) getCompressedData(data []byte, token []byte, c compression.Compression, clientId string) (compressed []byte, cerr *ce.CommonError) {
+	if srv.compressedCache == nil {
+		return compressData(data, c, clientId)
+	}
+
+	key := compressedCacheKey(token, c)
+
+	var err error
+	compressed, err = srv.compressedCache.GetRecord(key)
+	if err == nil {
+		srv.compressedMonitor.hits.Add(1)
+		return compressed, nil
+	}
+	srv.compressedMonitor.countMiss(key)
+
+	compressed, cerr = compressData(data, c, clientId)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	err = srv.compressedCache.AddRecord(key, compressed)
 	if err != nil {
 		return nil, ce.NewServerError(err.Error(), 0, 0, clientId)
 	}
+	srv.compressedMonitor.add(key, len(compressed))
 
-	// Save data in the cache.
-	err = srv.cache.AddRecord(uid, data)
+	return compressed, nil
+}
+
+// compressData compresses the data.
+// Returns a detailed error.
+func compressData(data []byte, c compression.Compression, clientId string) (compressed []byte, cerr *ce.CommonError) {
+	compressed, err := c.Compress(data)
 	if err != nil {
 		return nil, ce.NewServerError(err.Error(), 0, 0, clientId)
 	}
 
-	return data, nil
+	return compressed, nil
+}
+
+// chooseCompression selects a compression method for the connection's
+// features. Gzip is preferred as it is understood by all HTTP clients.
+func chooseCompression(features hello.Feature) (c compression.Compression) {
+	switch {
+	case features.Has(hello.Feature_CompressionGzip):
+		return compression.Compression_Gzip
+	case features.Has(hello.Feature_CompressionDeflate):
+		return compression.Compression_Deflate
+	default:
+		return compression.Compression_None
+	}
+}
+
+func compressedCacheKey(token []byte, c compression.Compression) (key string) {
+	return c.String() + ":" + hex.EncodeToString(token)
+}
+
+// calculateStreamHash calculates a SHA-256 hash sum of the stream's data.
+func calculateStreamHash(stream io.Reader) (hash []byte, err error) {
+	h := sha256.New()
+	_, err = io.Copy(h, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// calculateRange calculates the boundaries of a part of a data record. The
+// part is truncated when it exceeds the end of the record.
+func calculateRange(totalSize uint64, offset uint64, length uint64) (start uint64, end uint64, err error) {
+	if offset > totalSize {
+		return 0, 0, fmt.Errorf(ErrRangeIsNotValid, offset, totalSize)
+	}
+
+	if length > totalSize-offset {
+		return offset, totalSize, nil
+	}
+
+	return offset, offset + length, nil
 }
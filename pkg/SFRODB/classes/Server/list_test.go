@@ -0,0 +1,73 @@
+package server_test
+
+import (
+	"slices"
+	"testing"
+
+	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/sfrodbtest"
+)
+
+func Test_ListRecords_AllPages(t *testing.T) {
+	// With the '.txt' extension, the file 'a-b.txt' is sorted before the file
+	// 'a.txt', while the UID 'a' is sorted before the UID 'a-b'.
+	expected := []string{"a", "a-b", "a0", "b", "b-c", "b_d", "c"}
+
+	records := make(map[string][]byte)
+	for _, u := range expected {
+		records[u] = []byte(u)
+	}
+
+	ts := sfrodbtest.NewServer(t, records)
+	cli := ts.NewClient()
+
+	for _, limit := range []uint16{1, 2, 3, 7, 0} {
+		var listed, page []string
+		var cursor string
+		for {
+			var cerr *ce.CommonError
+			page, cursor, cerr = cli.ListRecords("", cursor, limit)
+			if cerr != nil {
+				t.Fatal(cerr)
+			}
+
+			listed = append(listed, page...)
+			if len(cursor) == 0 {
+				break
+			}
+			if len(listed) > len(expected) {
+				t.Fatalf("limit %v: too many records: %v", limit, listed)
+			}
+		}
+
+		if !slices.Equal(listed, expected) {
+			t.Fatalf("limit %v: expected %v, got %v", limit, expected, listed)
+		}
+	}
+}
+
+func Test_ListRecords_Prefix(t *testing.T) {
+	ts := sfrodbtest.NewServer(t, map[string][]byte{
+		"a":   []byte("1"),
+		"a-b": []byte("2"),
+		"ab":  []byte("3"),
+		"b":   []byte("4"),
+	})
+	cli := ts.NewClient()
+
+	uids, cursor, cerr := cli.ListRecords("a", "", 2)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !slices.Equal(uids, []string{"a", "a-b"}) || (cursor != "a-b") {
+		t.Fatalf("first page: %v, %q", uids, cursor)
+	}
+
+	uids, cursor, cerr = cli.ListRecords("a", cursor, 2)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !slices.Equal(uids, []string{"ab"}) || (len(cursor) != 0) {
+		t.Fatalf("second page: %v, %q", uids, cursor)
+	}
+}
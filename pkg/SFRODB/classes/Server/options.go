@@ -0,0 +1,184 @@
+package server
+
+import (
+	"errors"
+	"net"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Auth"
+	ds "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/DataSettings"
+	ss "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerSettings"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+	ts "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/TlsSettings"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/socket"
+)
+
+// ListenAddressDefault is the address used by a server created with options
+// when a listener is not given: an ephemeral port of the loopback interface.
+const ListenAddressDefault = "127.0.0.1:0"
+
+const (
+	ErrOptionsAreNotSet      = "options are not set"
+	ErrDataSettingsAreNotSet = "data settings are not set"
+)
+
+// Options are settings of a server which is embedded into another program.
+// Unlike the server's settings, they are not read from a file and do not
+// need fixed ports.
+type Options struct {
+	// Data Settings, including the data folder and the limits of cache.
+	Data *ds.DataSettings
+
+	// Storage of data files. It is optional, when it is not set, data files
+	// are stored in the folder or in the archive of the data settings.
+	// Server closes the storage when it stops, if the storage has the
+	// 'Close' method.
+	Storage storage.Storage
+
+	// Listeners of the main and the auxiliary ports. Any listeners may be
+	// used, e.g. TCP listeners on port zero. Server closes them when it
+	// stops. When a listener is not set, server listens on the default
+	// address, see 'ListenAddressDefault'.
+	MainListener net.Listener
+	AuxListener  net.Listener
+
+	// Optional parameters, see the server's settings.
+	Tls                    *ts.TlsSettings
+	AuxSecret              auth.Secret
+	ChangesScanIntervalSec uint
+	WarmManifest           string
+	ShutdownTimeoutSec     uint
+	RequestsInFlightMax    uint
+}
+
+func (opts *Options) Check() (err error) {
+	if opts.Data == nil {
+		return errors.New(ErrDataSettingsAreNotSet)
+	}
+
+	if opts.Storage == nil {
+		err = opts.Data.Check()
+	} else {
+		err = opts.Data.CheckWithoutFolder()
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.Tls != nil {
+		err = opts.Tls.CheckForServer()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(opts.AuxSecret) > 0 {
+		err = opts.AuxSecret.Check()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settings converts the options into server's settings. Addresses of the
+// settings are taken from the listeners when the server starts.
+func (opts *Options) settings() (stn *ss.ServerSettings) {
+	return &ss.ServerSettings{
+		Data:                   opts.Data,
+		Tls:                    opts.Tls,
+		AuxSecret:              opts.AuxSecret,
+		ChangesScanIntervalSec: opts.ChangesScanIntervalSec,
+		WarmManifest:           opts.WarmManifest,
+		ShutdownTimeoutSec:     opts.ShutdownTimeoutSec,
+		RequestsInFlightMax:    opts.RequestsInFlightMax,
+	}
+}
+
+// NewWithOptions creates a server which is embedded into another program.
+func NewWithOptions(opts *Options) (srv *Server, err error) {
+	if opts == nil {
+		return nil, errors.New(ErrOptionsAreNotSet)
+	}
+
+	err = opts.Check()
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err = newServer(opts.settings())
+	if err != nil {
+		return nil, err
+	}
+
+	srv.files = opts.Storage
+	if srv.files == nil {
+		srv.files, err = newStorage(opts.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	srv.isEmbedded = true
+	srv.mainListener = opts.MainListener
+	srv.auxListener = opts.AuxListener
+
+	if srv.mainListener == nil {
+		srv.mainListenerAddr, err = net.ResolveTCPAddr(protocol.LowLevelProtocol, ListenAddressDefault)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if srv.auxListener == nil {
+		srv.auxListenerAddr, err = net.ResolveTCPAddr(protocol.LowLevelProtocol, ListenAddressDefault)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	srv.applyBoundAddresses()
+	return srv, nil
+}
+
+// GetMainAddr returns the address of the main listener. After the server has
+// started, it is the actual address, e.g. with the port chosen by the system.
+func (srv *Server) GetMainAddr() (addr net.Addr) {
+	if srv.mainListener != nil {
+		return srv.mainListener.Addr()
+	}
+
+	return srv.mainListenerAddr
+}
+
+// GetAuxAddr returns the address of the auxiliary listener. After the server
+// has started, it is the actual address, e.g. with the port chosen by the
+// system.
+func (srv *Server) GetAuxAddr() (addr net.Addr) {
+	if srv.auxListener != nil {
+		return srv.auxListener.Addr()
+	}
+
+	return srv.auxListenerAddr
+}
+
+// applyBoundAddresses puts the actual addresses of the listeners into the
+// DSNs and the settings of an embedded server. Addresses of a server created
+// with a settings file are known in advance, they are not changed.
+func (srv *Server) applyBoundAddresses() {
+	if !srv.isEmbedded {
+		return
+	}
+
+	mainAddr, auxAddr := srv.GetMainAddr(), srv.GetAuxAddr()
+	srv.mainDsn = socket.AddrDsn(mainAddr)
+	srv.auxDsn = socket.AddrDsn(auxAddr)
+
+	var auxHost string
+	srv.settings.Hostname, srv.settings.MainPort, srv.settings.MainSocket = socket.SplitAddr(mainAddr)
+	auxHost, srv.settings.AuxPort, srv.settings.AuxSocket = socket.SplitAddr(auxAddr)
+	if len(srv.settings.Hostname) == 0 {
+		srv.settings.Hostname = auxHost
+	}
+}
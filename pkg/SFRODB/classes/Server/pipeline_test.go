@@ -0,0 +1,87 @@
+package server_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Server"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/sfrodbtest"
+)
+
+func Test_Pipelining(t *testing.T) {
+	const (
+		RecordsCount  = 10
+		RequestsCount = 50
+		WorkersCount  = 16
+	)
+
+	records := make(map[string][]byte)
+	for i := range RecordsCount {
+		records[fmt.Sprintf("r%d", i)] = []byte(fmt.Sprintf("data of record %d", i))
+	}
+
+	ts := sfrodbtest.NewServer(t, records)
+	cli := ts.NewClient()
+
+	// Responses are delayed, so that requests of all the workers are sent
+	// before the responses to the first ones are received.
+	ts.Faults.SetLatency(time.Millisecond)
+
+	var wg sync.WaitGroup
+	for w := range WorkersCount {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range RequestsCount {
+				uid := fmt.Sprintf("r%d", (w+i)%RecordsCount)
+				data, cerr := cli.ShowData(uid)
+				if cerr != nil {
+					t.Error(cerr)
+					return
+				}
+				if string(data) != string(records[uid]) {
+					t.Errorf("record %s: unexpected data: %q", uid, data)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// All the requests are sent through the two connections of the client.
+	n := ts.Faults.ConnectionsCount()
+	if n != 2 {
+		t.Fatalf("unexpected number of connections: %d", n)
+	}
+}
+
+func Test_Pipelining_InFlightLimit(t *testing.T) {
+	const WorkersCount = 8
+
+	// Requests of a connection are processed one by one, the others wait.
+	ts := sfrodbtest.NewServerWithOptions(t, map[string][]byte{"a": []byte("1")}, server.Options{
+		RequestsInFlightMax: 1,
+	})
+	cli := ts.NewClient()
+	ts.Faults.SetLatency(time.Millisecond)
+
+	var wg sync.WaitGroup
+	for range WorkersCount {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for range 10 {
+				data, cerr := cli.ShowData("a")
+				if (cerr != nil) || (string(data) != "1") {
+					t.Errorf("unexpected data: %q, %v", data, cerr)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
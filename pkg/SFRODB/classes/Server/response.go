@@ -1,15 +1,33 @@
 package server
 
 import (
+	"bytes"
+	"io"
+
 	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Compression"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Connection"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Event"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Hello"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Item"
+	rs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/RecordStat"
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Response"
+	si "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerInfo"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Stats"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
 )
 
-// respond_clientError tells the client about its (client's) error.
+// respond_clientError tells the client about its (client's) error. The code
+// and the text of the error are sent to the client.
 // Returns a detailed error.
-func (srv *Server) respond_clientError(con *connection.Connection) (cerr *ce.CommonError) {
-	resp, err := response.New_ClientError()
+func (srv *Server) respond_clientError(con *connection.Connection, requestId uint32, clientErr *ce.CommonError) (cerr *ce.CommonError) {
+	code := clientErr.GetCode()
+	if code == ec.ErrorCode_None {
+		code = ec.ErrorCode_Unknown
+	}
+
+	resp, err := response.New_ClientError(requestId, code, clientErr.Error())
 	if err != nil {
 		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
 	}
@@ -19,8 +37,8 @@ func (srv *Server) respond_clientError(con *connection.Connection) (cerr *ce.Com
 
 // respond_ok tells the client about its (client's) success.
 // Returns a detailed error.
-func (srv *Server) respond_ok(con *connection.Connection) (cerr *ce.CommonError) {
-	resp, err := response.New_OK()
+func (srv *Server) respond_ok(con *connection.Connection, requestId uint32) (cerr *ce.CommonError) {
+	resp, err := response.New_OK(requestId)
 	if err != nil {
 		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
 	}
@@ -31,8 +49,41 @@ func (srv *Server) respond_ok(con *connection.Connection) (cerr *ce.CommonError)
 // respond_closingConnection tells the client that server is going to close the
 // connection.
 // Returns a detailed error.
-func (srv *Server) respond_closingConnection(con *connection.Connection) (cerr *ce.CommonError) {
-	resp, err := response.New_ClosingConnection()
+func (srv *Server) respond_closingConnection(con *connection.Connection, requestId uint32) (cerr *ce.CommonError) {
+	resp, err := response.New_ClosingConnection(requestId)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_hello tells the client about the server.
+// Returns a detailed error.
+func (srv *Server) respond_hello(con *connection.Connection, requestId uint32, h *hello.Hello) (cerr *ce.CommonError) {
+	resp, err := response.New_Hello(requestId, h)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_pong answers a ping.
+// Returns a detailed error.
+func (srv *Server) respond_pong(con *connection.Connection, requestId uint32) (cerr *ce.CommonError) {
+	resp, err := response.New_Pong(requestId)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_authChallenge sends a challenge to the client.
+// Returns a detailed error.
+func (srv *Server) respond_authChallenge(con *connection.Connection, requestId uint32, challenge []byte) (cerr *ce.CommonError) {
+	resp, err := response.New_AuthChallenge(requestId, challenge)
 	if err != nil {
 		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
 	}
@@ -42,8 +93,194 @@ func (srv *Server) respond_closingConnection(con *connection.Connection) (cerr *
 
 // respond_showingData tells the client that server is showing data.
 // Returns a detailed error.
-func (srv *Server) respond_showingData(con *connection.Connection, data []byte) (cerr *ce.CommonError) {
-	resp, err := response.New_ShowingData(data)
+func (srv *Server) respond_showingData(con *connection.Connection, requestId uint32, data []byte) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingData(requestId, data)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_showingCompressedData tells the client that server is showing
+// compressed data.
+// Returns a detailed error.
+func (srv *Server) respond_showingCompressedData(con *connection.Connection, requestId uint32, c compression.Compression, data []byte) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingCompressedData(requestId, c, data)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_showingDataStream tells the client that server is showing data
+// which is read from the stream.
+// Returns a detailed error.
+func (srv *Server) respond_showingDataStream(con *connection.Connection, requestId uint32, stream io.Reader, streamSize int64) (cerr *ce.CommonError) {
+	if streamSize > protocol.ContentLenMax {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ItemIsTooLarge, response.ErrContentIsTooLong, 0, 0, con.ClientId())
+	}
+
+	resp, err := response.New_ShowingData(requestId, nil)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+	resp.Size = 0 // Size is calculated using the size of the stream.
+
+	return con.SendResponseStream(resp, stream, int(streamSize))
+}
+
+// respond_showingDataRange tells the client that server is showing a part of
+// data.
+// Returns a detailed error.
+func (srv *Server) respond_showingDataRange(con *connection.Connection, requestId uint32, totalSize uint64, data []byte) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingDataRange(requestId, totalSize, data)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_showingDataRangeStream tells the client that server is showing a
+// part of data which is read from the stream.
+// Returns a detailed error.
+func (srv *Server) respond_showingDataRangeStream(con *connection.Connection, requestId uint32, totalSize uint64, stream io.Reader, streamSize int64) (cerr *ce.CommonError) {
+	if streamSize > protocol.ContentLenMax-response.RangeTotalSizeLen {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ItemIsTooLarge, response.ErrContentIsTooLong, 0, 0, con.ClientId())
+	}
+
+	resp, err := response.New_ShowingDataRange(requestId, totalSize, nil)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+	resp.Size = 0 // Size is calculated using the size of the stream.
+
+	// Data of the response starts with the total size.
+	data := io.MultiReader(bytes.NewReader(resp.Data), stream)
+
+	return con.SendResponseStream(resp, data, len(resp.Data)+int(streamSize))
+}
+
+// respond_notModified tells the client that a data record is not modified.
+// Returns a detailed error.
+func (srv *Server) respond_notModified(con *connection.Connection, requestId uint32) (cerr *ce.CommonError) {
+	resp, err := response.New_NotModified(requestId)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_showingVersionedData tells the client that server is showing data
+// with its version token.
+// Returns a detailed error.
+func (srv *Server) respond_showingVersionedData(con *connection.Connection, requestId uint32, token []byte, data []byte) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingVersionedData(requestId, token, data)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_showingVersionedDataStream tells the client that server is showing
+// data, which is read from the stream, with its version token.
+// Returns a detailed error.
+func (srv *Server) respond_showingVersionedDataStream(con *connection.Connection, requestId uint32, token []byte, stream io.Reader, streamSize int64) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingVersionedData(requestId, token, nil)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	if streamSize > int64(protocol.ContentLenMax-len(resp.Data)) {
+		return ce.NewClientErrorWithCode(ec.ErrorCode_ItemIsTooLarge, response.ErrContentIsTooLong, 0, 0, con.ClientId())
+	}
+	resp.Size = 0 // Size is calculated using the size of the stream.
+
+	// Data of the response starts with the version token.
+	data := io.MultiReader(bytes.NewReader(resp.Data), stream)
+
+	return con.SendResponseStream(resp, data, len(resp.Data)+int(streamSize))
+}
+
+// respond_showingDataMany tells the client that server is showing many data
+// records.
+// Returns a detailed error.
+func (srv *Server) respond_showingDataMany(con *connection.Connection, requestId uint32, items []*item.Item) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingDataMany(requestId, items)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_showingRecordStat tells the client that server is showing
+// metadata of a data record.
+// Returns a detailed error.
+func (srv *Server) respond_showingRecordStat(con *connection.Connection, requestId uint32, stat *rs.RecordStat) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingRecordStat(requestId, stat)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_showingRecordList tells the client that server is showing a page of
+// a list of records.
+// Returns a detailed error.
+func (srv *Server) respond_showingRecordList(con *connection.Connection, requestId uint32, uids []string, nextCursor string) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingRecordList(requestId, uids, nextCursor)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_event sends an event to a watching client.
+// Returns a detailed error.
+func (srv *Server) respond_event(con *connection.Connection, requestId uint32, e *event.Event) (cerr *ce.CommonError) {
+	resp, err := response.New_Event(requestId, e)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_recordsWarmed tells the client about a result of a warm-up of
+// cache.
+// Returns a detailed error.
+func (srv *Server) respond_recordsWarmed(con *connection.Connection, requestId uint32, loaded int, failed int) (cerr *ce.CommonError) {
+	resp, err := response.New_RecordsWarmed(requestId, uint32(loaded), uint32(failed))
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_showingCacheStats sends statistics of the cache to the client.
+// Returns a detailed error.
+func (srv *Server) respond_showingCacheStats(con *connection.Connection, requestId uint32, cs *stats.CacheStats) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingCacheStats(requestId, cs)
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
+	}
+
+	return con.SendResponseMessage(resp)
+}
+
+// respond_showingServerInfo sends information about the server to the
+// client.
+// Returns a detailed error.
+func (srv *Server) respond_showingServerInfo(con *connection.Connection, requestId uint32, info *si.ServerInfo) (cerr *ce.CommonError) {
+	resp, err := response.New_ShowingServerInfo(requestId, info)
 	if err != nil {
 		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
 	}
@@ -53,8 +290,8 @@ func (srv *Server) respond_showingData(con *connection.Connection, data []byte)
 
 // respond_recordExists tells the client that a record exists.
 // Returns a detailed error.
-func (srv *Server) respond_recordExists(con *connection.Connection) (cerr *ce.CommonError) {
-	resp, err := response.New_RecordExists()
+func (srv *Server) respond_recordExists(con *connection.Connection, requestId uint32) (cerr *ce.CommonError) {
+	resp, err := response.New_RecordExists(requestId)
 	if err != nil {
 		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
 	}
@@ -64,8 +301,8 @@ func (srv *Server) respond_recordExists(con *connection.Connection) (cerr *ce.Co
 
 // respond_recordDoesNotExist tells the client that a record does not exist.
 // Returns a detailed error.
-func (srv *Server) respond_recordDoesNotExist(con *connection.Connection) (cerr *ce.CommonError) {
-	resp, err := response.New_RecordDoesNotExist()
+func (srv *Server) respond_recordDoesNotExist(con *connection.Connection, requestId uint32) (cerr *ce.CommonError) {
+	resp, err := response.New_RecordDoesNotExist(requestId)
 	if err != nil {
 		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
 	}
@@ -75,8 +312,8 @@ func (srv *Server) respond_recordDoesNotExist(con *connection.Connection) (cerr
 
 // respond_fileExists tells the client that a file exists.
 // Returns a detailed error.
-func (srv *Server) respond_fileExists(con *connection.Connection) (cerr *ce.CommonError) {
-	resp, err := response.New_FileExists()
+func (srv *Server) respond_fileExists(con *connection.Connection, requestId uint32) (cerr *ce.CommonError) {
+	resp, err := response.New_FileExists(requestId)
 	if err != nil {
 		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
 	}
@@ -86,8 +323,8 @@ func (srv *Server) respond_fileExists(con *connection.Connection) (cerr *ce.Comm
 
 // respond_fileDoesNotExist tells the client that a file does not exist.
 // Returns a detailed error.
-func (srv *Server) respond_fileDoesNotExist(con *connection.Connection) (cerr *ce.CommonError) {
-	resp, err := response.New_FileDoesNotExist()
+func (srv *Server) respond_fileDoesNotExist(con *connection.Connection, requestId uint32) (cerr *ce.CommonError) {
+	resp, err := response.New_FileDoesNotExist(requestId)
 	if err != nil {
 		return ce.NewServerError(err.Error(), 0, 0, con.ClientId())
 	}
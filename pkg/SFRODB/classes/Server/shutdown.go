@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Client"
+	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Connection"
+	ae "github.com/vault-thirteen/auxie/errors"
+)
+
+const (
+	ErrServerIsNotRunning = "server is not running"
+	ErrShutdownIsNotClean = "connections are not finished in time: "
+	MsgShuttingDown       = "Shutting down the Server ..."
+	MsgServerHasStopped   = "Server has stopped."
+)
+
+// BrokenConnectionsTimeoutSec is the time given to the handlers of broken
+// connections and to the background tasks to finish before the storage is
+// closed.
+const BrokenConnectionsTimeoutSec = 5
+
+const ErrTasksAreNotFinished = "tasks are not finished after breaking connections"
+
+// Stop stops the server gracefully, see the 'Shutdown' method. Connections
+// which are not finished within the shutdown timeout of the settings are
+// broken.
+func (srv *Server) Stop() (cerr *ce.CommonError) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(srv.settings.GetShutdownTimeoutSec()))
+	defer cancel()
+
+	return srv.Shutdown(ctx)
+}
+
+// Shutdown stops the server gracefully. Server stops accepting new
+// connections and stops reading new requests. Requests which are being
+// processed are finished, then each client receives the 'closing connection'
+// status and its connection is closed. Shutdown returns when all the
+// connections are finished and all the background tasks are stopped. When the
+// context is done before that, remaining connections are broken and an error
+// is returned; the storage is closed after their handlers have stopped, or
+// after the 'BrokenConnectionsTimeoutSec' timeout.
+func (srv *Server) Shutdown(ctx context.Context) (cerr *ce.CommonError) {
+	if !srv.isRunning.CompareAndSwap(true, false) {
+		return ce.NewServerError(ErrServerIsNotRunning, 0, 0, client.ClientIdNone)
+	}
+
+	log.Println(MsgShuttingDown)
+	close(srv.stopSignal)
+
+	// Stop accepting new connections.
+	err := ae.Combine(srv.mainListener.Close(), srv.auxListener.Close())
+
+	// Stop reading new requests.
+	srv.interruptConnections()
+
+	finished := make(chan struct{})
+	go func() {
+		srv.handlers.Wait()
+		srv.workers.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		srv.breakConnections()
+		err = ae.Combine(err, errors.New(ErrShutdownIsNotClean+ctx.Err().Error()))
+
+		// Handlers of broken connections stop at once, so the storage is not
+		// closed under them.
+		select {
+		case <-finished:
+		case <-time.After(time.Second * BrokenConnectionsTimeoutSec):
+			err = ae.Combine(err, errors.New(ErrTasksAreNotFinished))
+		}
+	}
+
+	// Storages keeping files open, e.g. archives, are closed.
+	closer, ok := srv.files.(io.Closer)
+	if ok {
+		err = ae.Combine(err, closer.Close())
+	}
+
+	if err != nil {
+		return ce.NewServerError(err.Error(), 0, 0, client.ClientIdNone)
+	}
+
+	log.Println(MsgServerHasStopped)
+	return nil
+}
+
+// addConnection registers an open connection. Connections are not
+// registered when the server is stopping.
+func (srv *Server) addConnection(con *connection.Connection) (ok bool) {
+	srv.connectionsLock.Lock()
+	defer srv.connectionsLock.Unlock()
+
+	if !srv.isRunning.Load() {
+		return false
+	}
+
+	srv.connections[con] = true
+	return true
+}
+
+// removeConnection unregisters a finished connection.
+func (srv *Server) removeConnection(con *connection.Connection) {
+	srv.connectionsLock.Lock()
+	defer srv.connectionsLock.Unlock()
+
+	delete(srv.connections, con)
+}
+
+// interruptConnections stops reading requests of all the open connections.
+// Each connection is finished after its requests being processed are
+// finished.
+func (srv *Server) interruptConnections() {
+	srv.connectionsLock.Lock()
+	defer srv.connectionsLock.Unlock()
+
+	for con := range srv.connections {
+		cerr := con.InterruptReading()
+		if cerr != nil {
+			log.Println(cerr)
+			_ = con.Break()
+		}
+	}
+}
+
+// breakConnections closes all the open connections immediately.
+func (srv *Server) breakConnections() {
+	srv.connectionsLock.Lock()
+	defer srv.connectionsLock.Unlock()
+
+	for con := range srv.connections {
+		cerr := con.Break()
+		if cerr != nil {
+			log.Println(cerr)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/sfrodbtest"
+)
+
+func Test_Shutdown_Idle(t *testing.T) {
+	ts := sfrodbtest.NewServer(t, map[string][]byte{"a": []byte("1")})
+	cli := ts.NewClient()
+
+	events, cerr := cli.Watch(context.Background())
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	cerr = ts.Server.Shutdown(context.Background())
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if ts.Server.IsRunning() {
+		t.Fatal("server is running")
+	}
+
+	// Watches are finished by the server.
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("unexpected event")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("watch is not finished")
+	}
+
+	_, cerr = cli.ShowData("a")
+	if cerr == nil {
+		t.Fatal("server works after shutdown")
+	}
+
+	cerr = ts.Server.Stop()
+	if cerr == nil {
+		t.Fatal("server is stopped twice")
+	}
+}
+
+func Test_Shutdown_Timeout(t *testing.T) {
+	// The record is streamed, and it does not fit into buffers of sockets.
+	big := bytes.Repeat([]byte("0123456789abcdef"), sfrodbtest.CachedItemVolumeMaxDefault)
+	ts := sfrodbtest.NewServer(t, map[string][]byte{"big": big})
+	cli := ts.NewClient()
+
+	stream, _, cerr := cli.ShowDataStream("big")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	// The stream is not read, so the connection is not finished in time.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+
+	cerr = ts.Server.Shutdown(ctx)
+	if cerr == nil {
+		t.Fatal("unfinished connection is not reported")
+	}
+
+	_, err := io.ReadAll(stream)
+	if err == nil {
+		t.Fatal("stream is not broken")
+	}
+}
@@ -0,0 +1,37 @@
+package server_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/sfrodbtest"
+)
+
+func Test_StatRecord_OutdatedCache(t *testing.T) {
+	ts := sfrodbtest.NewServer(t, map[string][]byte{"a": []byte("1")})
+	cli := ts.NewClient()
+
+	_, cerr := cli.ShowData("a")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	stat, cerr := cli.StatRecord("a")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if !stat.IsCached || (stat.Size != 1) || (stat.Hash != sha256.Sum256([]byte("1"))) {
+		t.Fatalf("cached record: %+v", stat)
+	}
+
+	// The file is changed while its old version is cached.
+	ts.PutRecord("a", []byte("22"))
+
+	stat, cerr = cli.StatRecord("a")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if (stat.Size != 2) || (stat.Hash != sha256.Sum256([]byte("22"))) {
+		t.Fatalf("changed record: %+v", stat)
+	}
+}
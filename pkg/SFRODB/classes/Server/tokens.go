@@ -0,0 +1,101 @@
+package server
+
+import (
+	"io"
+	"sync"
+
+	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+)
+
+// StreamTokensCountMax is the maximum number of kept version tokens of
+// records which are too large for cache.
+const StreamTokensCountMax = 1024
+
+// streamTokens are version tokens of records which are read from their files,
+// e.g. records which are too large for cache. Such records are not kept in
+// memory, so their tokens are kept separately together with states of their
+// files. A token is valid while the state of its file is not changed.
+type streamTokens struct {
+	tokens map[string]streamToken
+	lock   *sync.Mutex
+}
+
+// streamToken is a version token of a record and the state of its file when
+// the token was calculated.
+type streamToken struct {
+	state storage.FileState
+	token []byte
+}
+
+func newStreamTokens() (st *streamTokens) {
+	return &streamTokens{
+		tokens: make(map[string]streamToken),
+		lock:   new(sync.Mutex),
+	}
+}
+
+// get returns the token of a record when the state of its file is the same.
+func (st *streamTokens) get(uid string, state storage.FileState) (token []byte, ok bool) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	var t streamToken
+	t, ok = st.tokens[uid]
+	if !ok || !t.state.IsSameAs(state) {
+		return nil, false
+	}
+
+	return t.token, true
+}
+
+// put keeps the token of a record. When there are too many tokens, an
+// arbitrary one is dropped.
+func (st *streamTokens) put(uid string, state storage.FileState, token []byte) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	_, exists := st.tokens[uid]
+	if !exists && (len(st.tokens) >= StreamTokensCountMax) {
+		for u := range st.tokens {
+			delete(st.tokens, u)
+			break
+		}
+	}
+
+	st.tokens[uid] = streamToken{state: state, token: token}
+}
+
+// getStreamToken returns the version token of a record which is read from its
+// file. The stream is read only when the token is not known for the current
+// state of the file. A new token is kept only when the file has not been
+// changed while it was read.
+// Returns a detailed error.
+func (srv *Server) getStreamToken(uid string, stream io.ReaderAt, streamSize int64, clientId string) (token []byte, cerr *ce.CommonError) {
+	state, cerr := srv.statFile(uid, clientId)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	var ok bool
+	token, ok = srv.streamTokens.get(uid, state)
+	if ok && (state.Size == streamSize) {
+		return token, nil
+	}
+
+	token, err := calculateStreamHash(io.NewSectionReader(stream, 0, streamSize))
+	if err != nil {
+		return nil, ce.NewServerError(err.Error(), 0, 0, clientId)
+	}
+
+	stateAfter, cerr := srv.statFile(uid, clientId)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if stateAfter.IsSameAs(state) && (state.Size == streamSize) {
+		srv.streamTokens.put(uid, state, token)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Client"
+	ce "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/CommonError"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+)
+
+const (
+	// ManifestCommentPrefix starts a comment line of a warm-up manifest.
+	ManifestCommentPrefix = "#"
+
+	// ManifestPrefixSuffix ends a line of a warm-up manifest which is a
+	// prefix of UIDs rather than a single UID.
+	ManifestPrefixSuffix = "*"
+)
+
+const (
+	ErrRecordIsTooLargeForCache = "record is too large for cache: %s"
+	MsgWarmingCache             = "Warming the Cache ..."
+	MsgCacheIsWarmed            = "Cache is warmed: %d loaded, %d failed."
+	MsgWarmUpHasFailed          = "Warm-up of cache has failed: "
+)
+
+// warmRecords loads the records into cache. Records which can not be loaded
+// are counted as failed, the reasons are logged only for server's errors.
+// Loading stops when the loaded records fill the cache, so that they do not
+// push each other out; the rest of records are counted as failed.
+func (srv *Server) warmRecords(uids []string, clientId string) (loaded int, failed int) {
+	var volume int
+	var size int
+	var isCacheFull bool
+	var cerr *ce.CommonError
+	for i, u := range uids {
+		if !srv.isRunning.Load() {
+			return loaded, failed + len(uids) - i
+		}
+
+		size, isCacheFull, cerr = srv.warmRecord(u, srv.settings.Data.CacheVolumeMax-volume, clientId)
+		if isCacheFull {
+			return loaded, failed + len(uids) - i
+		}
+		if cerr != nil {
+			if cerr.IsServerError() {
+				log.Println(cerr)
+			}
+			failed++
+			continue
+		}
+
+		volume += size
+		loaded++
+	}
+
+	return loaded, failed
+}
+
+// warmRecord loads a single record into cache. Records which are already
+// cached are not read again. The free volume is the volume of cache which
+// is not used by other warmed records, the record is not loaded when it does
+// not fit into it. Warm-up is not a request for the record, so it is counted
+// neither as a cache hit nor as a cache miss.
+// Returns a detailed error.
+func (srv *Server) warmRecord(uid string, freeVolume int, clientId string) (size int, isCacheFull bool, cerr *ce.CommonError) {
+	data, _, ok := srv.lookupCachedRecord(uid)
+	if ok {
+		return len(data), false, nil
+	}
+
+	state, cerr := srv.statFile(uid, clientId)
+	if cerr != nil {
+		return 0, false, cerr
+	}
+
+	if state.Size > int64(srv.settings.Data.CachedItemVolumeMax) {
+		return 0, false, ce.NewClientError(fmt.Sprintf(ErrRecordIsTooLargeForCache, uid), 0, 0, clientId)
+	}
+	if state.Size > int64(freeVolume) {
+		return 0, true, nil
+	}
+
+	var stream storage.File
+	data, _, stream, _, cerr = srv.readRecord(uid, clientId)
+	if cerr != nil {
+		return 0, false, cerr
+	}
+
+	// The file has grown since it was checked.
+	if stream != nil {
+		err := stream.Close()
+		if err != nil {
+			return 0, false, ce.NewServerError(err.Error(), 0, 0, clientId)
+		}
+
+		return 0, false, ce.NewClientError(fmt.Sprintf(ErrRecordIsTooLargeForCache, uid), 0, 0, clientId)
+	}
+
+	return len(data), false, nil
+}
+
+// findRecords lists all the records which UIDs start with the prefix.
+// Returns a detailed error.
+func (srv *Server) findRecords(prefix string, clientId string) (uids []string, cerr *ce.CommonError) {
+	uids, _, cerr = srv.listRecords(prefix, "", math.MaxInt, clientId)
+	return uids, cerr
+}
+
+// warmFromManifest loads into cache the records listed in the manifest file
+// of the settings. Each line of the manifest is either a UID or a prefix of
+// UIDs followed by an asterisk. Empty lines and comments are ignored.
+func (srv *Server) warmFromManifest() {
+	defer srv.workers.Done()
+
+	log.Println(MsgWarmingCache)
+
+	uids, err := srv.readManifest(srv.settings.WarmManifest)
+	if err != nil {
+		log.Println(MsgWarmUpHasFailed + err.Error())
+		return
+	}
+
+	loaded, failed := srv.warmRecords(uids, client.ClientIdNone)
+	log.Println(fmt.Sprintf(MsgCacheIsWarmed, loaded, failed))
+}
+
+// readManifest reads a warm-up manifest and resolves its prefixes into UIDs.
+func (srv *Server) readManifest(filePath string) (uids []string, err error) {
+	var ba []byte
+	ba, err = os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	uids = make([]string, 0)
+	var found []string
+	var cerr *ce.CommonError
+	for _, line := range strings.Split(string(ba), "\n") {
+		line = strings.TrimSpace(line)
+		if (len(line) == 0) || strings.HasPrefix(line, ManifestCommentPrefix) {
+			continue
+		}
+
+		if !strings.HasSuffix(line, ManifestPrefixSuffix) {
+			uids = append(uids, line)
+			continue
+		}
+
+		found, cerr = srv.findRecords(strings.TrimSuffix(line, ManifestPrefixSuffix), client.ClientIdNone)
+		if cerr != nil {
+			return nil, cerr
+		}
+		uids = append(uids, found...)
+	}
+
+	return uids, nil
+}
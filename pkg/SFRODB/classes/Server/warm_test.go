@@ -0,0 +1,48 @@
+package server_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/sfrodbtest"
+)
+
+func Test_WarmRecords(t *testing.T) {
+	big := strings.Repeat("0", sfrodbtest.CachedItemVolumeMaxDefault+1)
+	ts := sfrodbtest.NewServer(t, map[string][]byte{
+		"a":   []byte("1"),
+		"b":   []byte("2"),
+		"big": []byte(big),
+	})
+	cli := ts.NewClient()
+
+	loaded, failed, cerr := cli.WarmRecords([]string{"a", "b", "big", "missing"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if (loaded != 2) || (failed != 2) {
+		t.Fatalf("loaded %d, failed %d", loaded, failed)
+	}
+
+	// Warm-up is not counted as requests for the records.
+	stats, cerr := cli.GetCacheStats()
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if (stats.Entries != 2) || (stats.Hits != 0) || (stats.Misses != 0) || (stats.DiskReads != 2) {
+		t.Fatalf("unexpected statistics: %+v", stats)
+	}
+
+	data, cerr := cli.ShowData("a")
+	if (cerr != nil) || (string(data) != "1") {
+		t.Fatalf("unexpected data: %q, %v", data, cerr)
+	}
+
+	stats, cerr = cli.GetCacheStats()
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	if (stats.Hits != 1) || (stats.Misses != 0) || (stats.DiskReads != 2) {
+		t.Fatalf("unexpected statistics: %+v", stats)
+	}
+}
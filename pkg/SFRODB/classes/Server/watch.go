@@ -0,0 +1,231 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Connection"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Event"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Storage"
+)
+
+const (
+	// WatcherQueueLen is the number of events which may wait for being sent
+	// to a single watching client. A client which is too slow to receive its
+	// events is disconnected, so that it does not delay other clients.
+	WatcherQueueLen = 1024
+)
+
+const (
+	ErrWatcherIsTooSlow = "watching client is too slow, events are lost: %s"
+	MsgScannerHasFailed = "Scan of changes has failed: "
+)
+
+// watcher is a client watching the changes.
+type watcher struct {
+	con *connection.Connection
+
+	// ID of the request which has started the watch. All the events are sent
+	// with this ID.
+	requestId uint32
+
+	events chan *event.Event
+
+	// Channel 'stopSignal' is closed when the watcher must stop, events which
+	// are still queued are not sent. Channel 'done' is closed when the
+	// watcher has stopped and sends nothing more.
+	stopSignal chan struct{}
+	stopOnce   *sync.Once
+	done       chan struct{}
+}
+
+// addWatcher registers a client watching the changes.
+func (srv *Server) addWatcher(con *connection.Connection, requestId uint32) (w *watcher) {
+	w = &watcher{
+		con:        con,
+		requestId:  requestId,
+		events:     make(chan *event.Event, WatcherQueueLen),
+		stopSignal: make(chan struct{}),
+		stopOnce:   new(sync.Once),
+		done:       make(chan struct{}),
+	}
+
+	srv.watchersLock.Lock()
+	defer srv.watchersLock.Unlock()
+
+	srv.watchers[w] = true
+	return w
+}
+
+// stop tells the watcher to stop without waiting for it.
+func (w *watcher) stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopSignal)
+	})
+}
+
+// isStopped tells whether the watcher has been told to stop.
+func (w *watcher) isStopped() bool {
+	select {
+	case <-w.stopSignal:
+		return true
+	default:
+		return false
+	}
+}
+
+// removeWatchers unregisters all the watchers of the connection and waits
+// for them to stop, so that no event is sent after this method returns.
+func (srv *Server) removeWatchers(con *connection.Connection) {
+	var removed []*watcher
+
+	srv.watchersLock.Lock()
+	for w := range srv.watchers {
+		if w.con == con {
+			delete(srv.watchers, w)
+			w.stop()
+			removed = append(removed, w)
+		}
+	}
+	srv.watchersLock.Unlock()
+
+	for _, w := range removed {
+		<-w.done
+	}
+}
+
+// runWatcher sends events to a watching client until the watcher is
+// stopped or until sending fails.
+func (srv *Server) runWatcher(w *watcher) {
+	defer close(w.done)
+
+	var e *event.Event
+	for {
+		select {
+		case <-w.stopSignal:
+			return
+		case e = <-w.events:
+		}
+
+		// A stopped watcher does not send the events which are queued.
+		if w.isStopped() {
+			return
+		}
+
+		cerr := srv.respond_event(w.con, w.requestId, e)
+		if cerr != nil {
+			if !w.con.IsBroken() {
+				log.Println(cerr)
+				_ = w.con.Break()
+			}
+			return
+		}
+	}
+}
+
+// publishEvent sends the event to all the watching clients. Clients which
+// queues are full are disconnected. Breaking a connection does not wait for
+// its pending writes, so a slow client does not delay others. Watchers of
+// such clients stay registered until their connections are finished.
+func (srv *Server) publishEvent(e *event.Event) {
+	srv.watchersLock.Lock()
+	defer srv.watchersLock.Unlock()
+
+	for w := range srv.watchers {
+		if w.isStopped() {
+			continue
+		}
+
+		select {
+		case w.events <- e:
+		default:
+			log.Println(fmt.Sprintf(ErrWatcherIsTooSlow, w.con.ClientId()))
+			w.stop()
+
+			cerr := w.con.Break()
+			if cerr != nil {
+				log.Println(cerr)
+			}
+		}
+	}
+}
+
+// runChangesScanner periodically scans the storage. Records which files
+// have been created, modified or removed since the previous scan are removed
+// from cache and watching clients are notified. A storage which may be
+// reloaded, e.g. an archive, is reloaded before each scan, so that a new
+// version of the archive is used automatically.
+func (srv *Server) runChangesScanner() {
+	defer srv.workers.Done()
+
+	states, err := srv.files.ScanFiles(srv.settings.Data.FileExtension)
+	if err != nil {
+		log.Println(MsgScannerHasFailed + err.Error())
+	}
+
+	ticker := time.NewTicker(time.Second * time.Duration(srv.settings.ChangesScanIntervalSec))
+	defer ticker.Stop()
+
+	var newStates map[string]storage.FileState
+	for {
+		select {
+		case <-ticker.C:
+		case <-srv.stopSignal:
+			log.Println("Changes scanner has stopped.")
+			return
+		}
+
+		r, ok := srv.files.(reloader)
+		if ok {
+			_, err = r.Reload()
+			if err != nil {
+				log.Println(MsgScannerHasFailed + err.Error())
+				continue
+			}
+		}
+
+		newStates, err = srv.files.ScanFiles(srv.settings.Data.FileExtension)
+		if err != nil {
+			log.Println(MsgScannerHasFailed + err.Error())
+			continue
+		}
+
+		if states != nil {
+			srv.processChanges(states, newStates)
+		}
+		states = newStates
+	}
+}
+
+// processChanges compares two states of the data folder.
+func (srv *Server) processChanges(oldStates map[string]storage.FileState, newStates map[string]storage.FileState) {
+	for relPath, newState := range newStates {
+		oldState, existed := oldStates[relPath]
+		if existed && oldState.IsSameAs(newState) {
+			continue
+		}
+
+		srv.processChangedFile(relPath)
+	}
+
+	for relPath := range oldStates {
+		_, exists := newStates[relPath]
+		if !exists {
+			srv.processChangedFile(relPath)
+		}
+	}
+}
+
+// processChangedFile removes the record of a changed file from cache and
+// notifies watching clients.
+func (srv *Server) processChangedFile(relPath string) {
+	u, ok := srv.getUidOfFile(relPath)
+	if !ok {
+		return
+	}
+
+	srv.uncacheRecord(u)
+	srv.publishEvent(event.New_RecordChanged(u))
+}
@@ -0,0 +1,177 @@
+package si
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Endianness"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
+)
+
+const (
+	ErrInfoIsInvalid = "server information is invalid"
+	ErrTextIsTooLong = "text is too long: %v"
+	ErrTooManyParams = "too many parameters: %v"
+)
+
+// BuildInfo describes the build of the server's program.
+type BuildInfo struct {
+	ProgramName    string
+	ProgramVersion string
+	GoVersion      string
+}
+
+// Parameter is a parameter of the server's settings in a text form.
+type Parameter struct {
+	Name  string
+	Value string
+}
+
+// ServerInfo is information about a running server.
+type ServerInfo struct {
+	Build BuildInfo
+
+	// Time when the server has been started and the time since then,
+	// measured by the server.
+	StartTime time.Time
+	Uptime    time.Duration
+
+	// Effective settings of the server. Secrets are redacted.
+	Settings []Parameter
+
+	// Numbers of currently open connections.
+	MainConnections uint32
+	AuxConnections  uint32
+}
+
+func NewFromBytes(ba []byte) (i *ServerInfo, err error) {
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{ba: ba, bo: bo}
+	i = &ServerInfo{}
+
+	i.Build.ProgramName = r.readText()
+	i.Build.ProgramVersion = r.readText()
+	i.Build.GoVersion = r.readText()
+	i.StartTime = time.Unix(0, int64(r.readUint64()))
+	i.Uptime = time.Duration(r.readUint64())
+	i.MainConnections = r.readUint32()
+	i.AuxConnections = r.readUint32()
+
+	count := int(r.readUint16())
+	i.Settings = make([]Parameter, 0, min(count, len(ba)))
+	for j := 0; (j < count) && (r.err == nil); j++ {
+		i.Settings = append(i.Settings, Parameter{Name: r.readText(), Value: r.readText()})
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.pos != len(ba) {
+		return nil, errors.New(ErrInfoIsInvalid)
+	}
+
+	return i, nil
+}
+
+func (i *ServerInfo) Bytes() (ba []byte, err error) {
+	if len(i.Settings) > math.MaxUint16 {
+		return nil, fmt.Errorf(ErrTooManyParams, len(i.Settings))
+	}
+
+	var bo endianness.ByteOrder
+	bo, err = protocol.Endianness.ByteOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	texts := []string{i.Build.ProgramName, i.Build.ProgramVersion, i.Build.GoVersion}
+	for _, p := range i.Settings {
+		texts = append(texts, p.Name, p.Value)
+	}
+	for _, t := range texts {
+		if len(t) > math.MaxUint16 {
+			return nil, fmt.Errorf(ErrTextIsTooLong, len(t))
+		}
+	}
+
+	ba = appendText(bo, nil, i.Build.ProgramName)
+	ba = appendText(bo, ba, i.Build.ProgramVersion)
+	ba = appendText(bo, ba, i.Build.GoVersion)
+	ba = bo.AppendUint64(ba, uint64(i.StartTime.UnixNano()))
+	ba = bo.AppendUint64(ba, uint64(i.Uptime))
+	ba = bo.AppendUint32(ba, i.MainConnections)
+	ba = bo.AppendUint32(ba, i.AuxConnections)
+	ba = bo.AppendUint16(ba, uint16(len(i.Settings)))
+	for _, p := range i.Settings {
+		ba = appendText(bo, ba, p.Name)
+		ba = appendText(bo, ba, p.Value)
+	}
+
+	return ba, nil
+}
+
+// appendText appends a text preceded by its size.
+func appendText(bo endianness.ByteOrder, ba []byte, text string) []byte {
+	ba = bo.AppendUint16(ba, uint16(len(text)))
+	return append(ba, text...)
+}
+
+// reader reads fields of encoded information. When data is too short, the
+// reader remembers the error and returns zero values.
+type reader struct {
+	ba  []byte
+	bo  endianness.ByteOrder
+	pos int
+	err error
+}
+
+// take returns the next bytes of data.
+func (r *reader) take(n int) (ba []byte) {
+	if (r.err != nil) || (r.pos+n > len(r.ba)) {
+		r.err = errors.New(ErrInfoIsInvalid)
+		return nil
+	}
+
+	ba = r.ba[r.pos : r.pos+n]
+	r.pos += n
+	return ba
+}
+
+func (r *reader) readUint16() uint16 {
+	ba := r.take(2)
+	if ba == nil {
+		return 0
+	}
+
+	return r.bo.Uint16(ba)
+}
+
+func (r *reader) readUint32() uint32 {
+	ba := r.take(4)
+	if ba == nil {
+		return 0
+	}
+
+	return r.bo.Uint32(ba)
+}
+
+func (r *reader) readUint64() uint64 {
+	ba := r.take(8)
+	if ba == nil {
+		return 0
+	}
+
+	return r.bo.Uint64(ba)
+}
+
+func (r *reader) readText() string {
+	size := int(r.readUint16())
+	return string(r.take(size))
+}
@@ -2,15 +2,29 @@ package ss
 
 import (
 	"errors"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Auth"
 	ds "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/DataSettings"
+	si "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ServerInfo"
+	ts "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/TlsSettings"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/socket"
 	ae "github.com/vault-thirteen/auxie/errors"
-	"github.com/vault-thirteen/auxie/number"
 	"github.com/vault-thirteen/auxie/reader"
 )
 
+// ShutdownTimeoutSecDefault is the time given to connections to finish when
+// the server stops, if it is not set in the settings.
+const ShutdownTimeoutSecDefault = 30
+
+// RequestsInFlightMaxDefault is the number of requests of a single
+// connection which are processed at the same time, if it is not set in the
+// settings.
+const RequestsInFlightMaxDefault = 64
+
 const (
 	ErrFileIsNotSet       = "file is not set"
 	ErrServerHostIsNotSet = "server host is not set"
@@ -34,8 +48,43 @@ type ServerSettings struct {
 	// A port which is used for auxiliary operations.
 	AuxPort uint16
 
+	// Paths to Unix sockets which are used instead of the ports when they
+	// are set.
+	MainSocket string
+	AuxSocket  string
+
 	// Data Settings.
 	Data *ds.DataSettings
+
+	// TLS Settings.
+	// They are optional, TLS is disabled when they are not set.
+	Tls *ts.TlsSettings
+
+	// Secret shared with clients of the auxiliary port.
+	// It is optional. When it is set, clients must authenticate themselves
+	// before using the auxiliary port.
+	AuxSecret auth.Secret
+
+	// Interval between scans of the data folder, in seconds. Changed files
+	// are removed from cache and watching clients are notified. It is
+	// optional, zero disables the scans.
+	ChangesScanIntervalSec uint
+
+	// Path to a manifest of records which are loaded into cache at start.
+	// Each line of the manifest is either a UID or a prefix of UIDs followed
+	// by an asterisk. It is optional, the cache is empty at start when it is
+	// not set.
+	WarmManifest string
+
+	// Time given to connections to finish when the server stops, in seconds.
+	// It is optional, zero means the default time.
+	ShutdownTimeoutSec uint
+
+	// Maximum number of requests of a single connection which are processed
+	// at the same time. Server does not read more requests of the connection
+	// until some of them are finished. It is optional, zero means the default
+	// number.
+	RequestsInFlightMax uint
 }
 
 func NewSettingsFromFile(filePath string) (stn *ServerSettings, err error) {
@@ -68,12 +117,12 @@ func NewSettingsFromFile(filePath string) (stn *ServerSettings, err error) {
 	// Server Hostname & Port.
 	stn.Hostname = strings.TrimSpace(string(buf[0]))
 
-	stn.MainPort, err = number.ParseUint16(strings.TrimSpace(string(buf[1])))
+	stn.MainPort, stn.MainSocket, err = socket.ParsePort(string(buf[1]))
 	if err != nil {
 		return stn, err
 	}
 
-	stn.AuxPort, err = number.ParseUint16(strings.TrimSpace(string(buf[2])))
+	stn.AuxPort, stn.AuxSocket, err = socket.ParsePort(string(buf[2]))
 	if err != nil {
 		return stn, err
 	}
@@ -84,23 +133,178 @@ func NewSettingsFromFile(filePath string) (stn *ServerSettings, err error) {
 		return stn, err
 	}
 
+	// Optional settings.
+	var line string
+	line, err = readOptionalLine(rdr)
+	if err != nil {
+		return stn, err
+	}
+
+	if len(line) > 0 {
+		stn.Tls, err = ts.ParseServerTlsSettings(line)
+		if err != nil {
+			return stn, err
+		}
+	}
+
+	line, err = readOptionalLine(rdr)
+	if err != nil {
+		return stn, err
+	}
+
+	if len(line) > 0 {
+		stn.AuxSecret = auth.Secret(line)
+	}
+
+	line, err = readOptionalLine(rdr)
+	if err != nil {
+		return stn, err
+	}
+
+	if len(line) > 0 {
+		var interval uint64
+		interval, err = strconv.ParseUint(line, 10, 32)
+		if err != nil {
+			return stn, err
+		}
+		stn.ChangesScanIntervalSec = uint(interval)
+	}
+
+	stn.WarmManifest, err = readOptionalLine(rdr)
+	if err != nil {
+		return stn, err
+	}
+
+	line, err = readOptionalLine(rdr)
+	if err != nil {
+		return stn, err
+	}
+
+	if len(line) > 0 {
+		var timeout uint64
+		timeout, err = strconv.ParseUint(line, 10, 32)
+		if err != nil {
+			return stn, err
+		}
+		stn.ShutdownTimeoutSec = uint(timeout)
+	}
+
+	line, err = readOptionalLine(rdr)
+	if err != nil {
+		return stn, err
+	}
+
+	if len(line) > 0 {
+		var requestsMax uint64
+		requestsMax, err = strconv.ParseUint(line, 10, 32)
+		if err != nil {
+			return stn, err
+		}
+		stn.RequestsInFlightMax = uint(requestsMax)
+	}
+
 	return stn, nil
 }
 
+// readOptionalLine reads a line which may be absent at the end of the file.
+// An absent line is returned as an empty string.
+func readOptionalLine(rdr *reader.Reader) (line string, err error) {
+	var ba []byte
+	ba, err = rdr.ReadLineEndingWithCRLF()
+	if (err != nil) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(ba)), nil
+}
+
+// Parameters returns the settings in a text form. Secrets are redacted.
+func (stn *ServerSettings) Parameters() (params []si.Parameter) {
+	params = []si.Parameter{
+		{Name: "File", Value: stn.File},
+		{Name: "Hostname", Value: stn.Hostname},
+		{Name: "MainPort", Value: portText(stn.MainPort, stn.MainSocket)},
+		{Name: "AuxPort", Value: portText(stn.AuxPort, stn.AuxSocket)},
+	}
+
+	if stn.Data != nil {
+		params = append(params,
+			si.Parameter{Name: "Folder", Value: stn.Data.Folder},
+			si.Parameter{Name: "Archive", Value: stn.Data.Archive},
+			si.Parameter{Name: "FileExtension", Value: stn.Data.FileExtension},
+			si.Parameter{Name: "CacheVolumeMax", Value: strconv.Itoa(stn.Data.CacheVolumeMax)},
+			si.Parameter{Name: "CachedItemVolumeMax", Value: strconv.Itoa(stn.Data.CachedItemVolumeMax)},
+			si.Parameter{Name: "CachedItemTTL", Value: strconv.FormatUint(uint64(stn.Data.CachedItemTTL), 10)},
+			si.Parameter{Name: "CompressedCacheVolumeMax", Value: strconv.Itoa(stn.Data.CompressedCacheVolumeMax)},
+		)
+	}
+
+	if stn.Tls != nil {
+		params = append(params,
+			si.Parameter{Name: "TlsCertFile", Value: stn.Tls.CertFile},
+			si.Parameter{Name: "TlsKeyFile", Value: stn.Tls.KeyFile},
+			si.Parameter{Name: "TlsCaFile", Value: stn.Tls.CaFile},
+		)
+	}
+
+	params = append(params,
+		si.Parameter{Name: "AuxSecret", Value: stn.AuxSecret.String()},
+		si.Parameter{Name: "ChangesScanIntervalSec", Value: strconv.FormatUint(uint64(stn.ChangesScanIntervalSec), 10)},
+		si.Parameter{Name: "WarmManifest", Value: stn.WarmManifest},
+		si.Parameter{Name: "ShutdownTimeoutSec", Value: strconv.FormatUint(uint64(stn.GetShutdownTimeoutSec()), 10)},
+		si.Parameter{Name: "RequestsInFlightMax", Value: strconv.FormatUint(uint64(stn.GetRequestsInFlightMax()), 10)},
+	)
+
+	return params
+}
+
+// portText returns a port or a path to a Unix socket in the format of the
+// settings file.
+func portText(port uint16, unixPath string) string {
+	if len(unixPath) > 0 {
+		return socket.UnixPrefix + unixPath
+	}
+
+	return strconv.FormatUint(uint64(port), 10)
+}
+
+// GetShutdownTimeoutSec returns the effective time given to connections to
+// finish when the server stops, in seconds.
+func (stn *ServerSettings) GetShutdownTimeoutSec() (timeout uint) {
+	if stn.ShutdownTimeoutSec == 0 {
+		return ShutdownTimeoutSecDefault
+	}
+
+	return stn.ShutdownTimeoutSec
+}
+
+// GetRequestsInFlightMax returns the effective number of requests of a
+// single connection which are processed at the same time.
+func (stn *ServerSettings) GetRequestsInFlightMax() (requestsMax uint) {
+	if stn.RequestsInFlightMax == 0 {
+		return RequestsInFlightMaxDefault
+	}
+
+	return stn.RequestsInFlightMax
+}
+
 func (stn *ServerSettings) Check() (err error) {
 	if len(stn.File) == 0 {
 		return errors.New(ErrFileIsNotSet)
 	}
 
-	if len(stn.Hostname) == 0 {
-		return errors.New(ErrServerHostIsNotSet)
+	// Host name is not needed for Unix sockets.
+	if (len(stn.MainSocket) == 0) || (len(stn.AuxSocket) == 0) {
+		if len(stn.Hostname) == 0 {
+			return errors.New(ErrServerHostIsNotSet)
+		}
 	}
 
-	if stn.MainPort == 0 {
+	if (stn.MainPort == 0) && (len(stn.MainSocket) == 0) {
 		return errors.New(ErrServerPortIsNotSet)
 	}
 
-	if stn.AuxPort == 0 {
+	if (stn.AuxPort == 0) && (len(stn.AuxSocket) == 0) {
 		return errors.New(ErrServerPortIsNotSet)
 	}
 
@@ -109,5 +313,19 @@ func (stn *ServerSettings) Check() (err error) {
 		return err
 	}
 
+	if stn.Tls != nil {
+		err = stn.Tls.CheckForServer()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(stn.AuxSecret) > 0 {
+		err = stn.AuxSecret.Check()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
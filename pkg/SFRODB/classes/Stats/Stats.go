@@ -0,0 +1,57 @@
+package stats
+
+const (
+	// CounterLen is the length of a single counter of the statistics.
+	CounterLen = 8
+
+	// CacheStatsEncodedLen is the length of encoded statistics of cache.
+	CacheStatsEncodedLen = CounterLen * 11
+
+	// CacheStatsEncodedLenMin is the length of encoded statistics of cache
+	// sent by older servers, which have no counters of compressed copies.
+	CacheStatsEncodedLenMin = CounterLen * 8
+)
+
+// CacheStats are statistics of the server's cache. Counters are collected
+// since the start of the server.
+type CacheStats struct {
+	// Requests for records which have been found or not found in cache.
+	Hits   uint64
+	Misses uint64
+
+	// Number of records in cache and the volume they use, in bytes.
+	Entries uint64
+	Volume  uint64
+
+	// Maximum volume of cache, in bytes.
+	VolumeMax uint64
+
+	// Number of records removed by the cache itself, either because of their
+	// TTL or to free the volume. Records removed by clients' requests are not
+	// counted.
+	Evictions uint64
+
+	// Number of reads of records from files.
+	DiskReads uint64
+
+	// Number of records which have not been cached because they are larger
+	// than the maximum volume of a single cached item.
+	RejectedRecords uint64
+
+	// Number of compressed copies of records in their own cache, the volume
+	// they use and the maximum volume of that cache, in bytes. The maximum
+	// volume is zero when compressed copies are not kept.
+	CompressedEntries   uint64
+	CompressedVolume    uint64
+	CompressedVolumeMax uint64
+}
+
+// HitRatio returns the ratio of hits to all the requests for records. When
+// there were no requests, the ratio is zero.
+func (cs *CacheStats) HitRatio() float64 {
+	if cs.Hits+cs.Misses == 0 {
+		return 0
+	}
+
+	return float64(cs.Hits) / float64(cs.Hits+cs.Misses)
+}
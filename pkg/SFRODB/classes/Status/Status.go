@@ -2,21 +2,37 @@ package status
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
 )
 
 const (
-	Status_Unknown            = Status(0)
-	Status_OK                 = Status(1)
-	Status_ClientError        = Status(2)
-	Status_ClosingConnection  = Status(3)
-	Status_ShowingData        = Status(4)
-	Status_RecordExists       = Status(5)
-	Status_RecordDoesNotExist = Status(6)
-	Status_FileExists         = Status(7)
-	Status_FileDoesNotExist   = Status(8)
+	Status_Unknown               = Status(0)
+	Status_OK                    = Status(1)
+	Status_ClientError           = Status(2)
+	Status_ClosingConnection     = Status(3)
+	Status_ShowingData           = Status(4)
+	Status_RecordExists          = Status(5)
+	Status_RecordDoesNotExist    = Status(6)
+	Status_FileExists            = Status(7)
+	Status_FileDoesNotExist      = Status(8)
+	Status_ShowingDataRange      = Status(9)
+	Status_ShowingDataMany       = Status(10)
+	Status_ShowingRecordStat     = Status(11)
+	Status_NotModified           = Status(12)
+	Status_ShowingVersionedData  = Status(13)
+	Status_Hello                 = Status(14)
+	Status_ShowingCompressedData = Status(15)
+	Status_AuthChallenge         = Status(16)
+	Status_Pong                  = Status(17)
+	Status_ShowingRecordList     = Status(18)
+	Status_Event                 = Status(19)
+	Status_RecordsWarmed         = Status(20)
+	Status_ShowingCacheStats     = Status(21)
+	Status_ShowingServerInfo     = Status(22)
+	Status_ItemIsTooLarge        = Status(23)
 )
 
 const (
@@ -54,6 +70,51 @@ func NewFromString(str string) (s Status, err error) {
 	case protocol.Status_FileDoesNotExist:
 		return Status_FileDoesNotExist, nil
 
+	case protocol.Status_ShowingDataRange:
+		return Status_ShowingDataRange, nil
+
+	case protocol.Status_ShowingDataMany:
+		return Status_ShowingDataMany, nil
+
+	case protocol.Status_ShowingRecordStat:
+		return Status_ShowingRecordStat, nil
+
+	case protocol.Status_NotModified:
+		return Status_NotModified, nil
+
+	case protocol.Status_ShowingVersionedData:
+		return Status_ShowingVersionedData, nil
+
+	case protocol.Status_Hello:
+		return Status_Hello, nil
+
+	case protocol.Status_ShowingCompressedData:
+		return Status_ShowingCompressedData, nil
+
+	case protocol.Status_AuthChallenge:
+		return Status_AuthChallenge, nil
+
+	case protocol.Status_Pong:
+		return Status_Pong, nil
+
+	case protocol.Status_ShowingRecordList:
+		return Status_ShowingRecordList, nil
+
+	case protocol.Status_Event:
+		return Status_Event, nil
+
+	case protocol.Status_RecordsWarmed:
+		return Status_RecordsWarmed, nil
+
+	case protocol.Status_ShowingCacheStats:
+		return Status_ShowingCacheStats, nil
+
+	case protocol.Status_ShowingServerInfo:
+		return Status_ShowingServerInfo, nil
+
+	case protocol.Status_ItemIsTooLarge:
+		return Status_ItemIsTooLarge, nil
+
 	default:
 		return Status_Unknown, fmt.Errorf(ErrUnknownStatusName, statusStr)
 	}
@@ -85,7 +146,62 @@ func (s Status) Bytes() ([]byte, error) {
 	case Status_FileDoesNotExist:
 		return []byte(protocol.Status_FileDoesNotExist), nil
 
+	case Status_ShowingDataRange:
+		return []byte(protocol.Status_ShowingDataRange), nil
+
+	case Status_ShowingDataMany:
+		return []byte(protocol.Status_ShowingDataMany), nil
+
+	case Status_ShowingRecordStat:
+		return []byte(protocol.Status_ShowingRecordStat), nil
+
+	case Status_NotModified:
+		return []byte(protocol.Status_NotModified), nil
+
+	case Status_ShowingVersionedData:
+		return []byte(protocol.Status_ShowingVersionedData), nil
+
+	case Status_Hello:
+		return []byte(protocol.Status_Hello), nil
+
+	case Status_ShowingCompressedData:
+		return []byte(protocol.Status_ShowingCompressedData), nil
+
+	case Status_AuthChallenge:
+		return []byte(protocol.Status_AuthChallenge), nil
+
+	case Status_Pong:
+		return []byte(protocol.Status_Pong), nil
+
+	case Status_ShowingRecordList:
+		return []byte(protocol.Status_ShowingRecordList), nil
+
+	case Status_Event:
+		return []byte(protocol.Status_Event), nil
+
+	case Status_RecordsWarmed:
+		return []byte(protocol.Status_RecordsWarmed), nil
+
+	case Status_ShowingCacheStats:
+		return []byte(protocol.Status_ShowingCacheStats), nil
+
+	case Status_ShowingServerInfo:
+		return []byte(protocol.Status_ShowingServerInfo), nil
+
+	case Status_ItemIsTooLarge:
+		return []byte(protocol.Status_ItemIsTooLarge), nil
+
 	default:
-		return nil, fmt.Errorf(ErrUnknownStatusName, s)
+		return nil, fmt.Errorf(ErrUnknownStatusName, strconv.Itoa(int(s)))
 	}
 }
+
+// String returns the name of the status as it is used in the protocol.
+func (s Status) String() string {
+	ba, err := s.Bytes()
+	if err != nil {
+		return strconv.Itoa(int(s))
+	}
+
+	return string(ba)
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	ErrFileDoesNotExist  = "file does not exist: %s"
+	ErrRelPathIsNotValid = "relative path is not valid"
+)
+
+// Storage is a storage of data files. Files are addressed by relative paths
+// which use forward slashes as separators. Paths containing '..' are not
+// valid. A storage must be safe for concurrent use.
+type Storage interface {
+	// OpenFile opens a file for reading and returns its size. The caller
+	// must close the file. Existence of a file is reported separately from
+	// an error, so that a missing file could be told from a failure.
+	OpenFile(relPath string) (fileExists bool, f File, fileSize int64, err error)
+
+	// FileExists checks existence of a file.
+	FileExists(relPath string) (fileExists bool, err error)
+
+	// StatFile returns the state of a file without reading it.
+	StatFile(relPath string) (fileExists bool, state FileState, err error)
+
+	// ListFiles returns relative paths of all the files having the
+	// extension. Paths are sorted.
+	ListFiles(extension string) (relPaths []string, err error)
+
+	// ScanFiles returns states of all the files having the extension by
+	// their relative paths. States of two scans may be compared to find
+	// changed files.
+	ScanFiles(extension string) (states map[string]FileState, err error)
+}
+
+// File is an opened file of a storage. Parts of a file may be read at any
+// offset.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Closer
+}
+
+// FileState is a state of a file which is used to find out whether the file
+// has been changed.
+type FileState struct {
+	Size    int64
+	ModTime time.Time
+
+	// Checksum of the file's contents, e.g. CRC-32 of a file of an archive.
+	// It is zero when the storage does not know it without reading the file.
+	Checksum uint32
+}
+
+// IsSameAs checks whether two states belong to the same version of a file.
+func (s FileState) IsSameAs(other FileState) (ok bool) {
+	return (s.Size == other.Size) && s.ModTime.Equal(other.ModTime) && (s.Checksum == other.Checksum)
+}
+
+// IsRelPathValid checks a relative path of a file. Paths leading outside the
+// storage are not valid.
+func IsRelPathValid(relPath string) (ok bool) {
+	return !strings.Contains(relPath, "..")
+}
+
+// NewBytesFile creates a file which contents are stored in memory. Closing
+// the file does nothing.
+func NewBytesFile(data []byte) (f File) {
+	return bytesFile{bytes.NewReader(data)}
+}
+
+// bytesFile is a file which contents are stored in memory.
+type bytesFile struct {
+	*bytes.Reader
+}
+
+// Close is the standard method of the 'io.Closer' interface.
+func (bf bytesFile) Close() (err error) {
+	return nil
+}
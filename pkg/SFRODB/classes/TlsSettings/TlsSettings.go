@@ -0,0 +1,169 @@
+package ts
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	ErrSyntax                 = "syntax error"
+	ErrCertFileIsNotSet       = "TLS certificate file is not set"
+	ErrKeyFileIsNotSet        = "TLS key file is not set"
+	ErrCaCertificateIsInvalid = "CA certificate is not valid: %s"
+)
+
+// TlsVersionMin is the minimal version of TLS.
+const TlsVersionMin = tls.VersionTLS12
+
+// TlsSettings are settings of TLS.
+type TlsSettings struct {
+	// 1. Certificate file.
+	// Server must have a certificate. Client needs a certificate only when
+	// server requires certificates of clients.
+	CertFile string
+
+	// 2. Key file of the certificate.
+	KeyFile string
+
+	// 3. File with certificates of trusted certificate authorities.
+	// When it is set on server, clients must present certificates signed by
+	// these authorities, i.e. mutual TLS is used. Client verifies the
+	// certificate of server using these authorities, or using the system's
+	// authorities when the file is not set.
+	CaFile string
+}
+
+// ParseServerTlsSettings parses TLS settings of a server. The format is
+// "certFile keyFile [caFile]".
+func ParseServerTlsSettings(line string) (ts *TlsSettings, err error) {
+	parts := strings.Fields(line)
+
+	switch len(parts) {
+	case 2:
+		ts = &TlsSettings{CertFile: parts[0], KeyFile: parts[1]}
+	case 3:
+		ts = &TlsSettings{CertFile: parts[0], KeyFile: parts[1], CaFile: parts[2]}
+	default:
+		return nil, errors.New(ErrSyntax)
+	}
+
+	return ts, nil
+}
+
+// ParseClientTlsSettings parses TLS settings of a client. The format is
+// "caFile [certFile keyFile]". A dash ("-") may be used instead of the CA
+// file to use the system's authorities.
+func ParseClientTlsSettings(line string) (ts *TlsSettings, err error) {
+	parts := strings.Fields(line)
+
+	switch len(parts) {
+	case 1:
+		ts = &TlsSettings{CaFile: parts[0]}
+	case 3:
+		ts = &TlsSettings{CaFile: parts[0], CertFile: parts[1], KeyFile: parts[2]}
+	default:
+		return nil, errors.New(ErrSyntax)
+	}
+
+	if ts.CaFile == "-" {
+		ts.CaFile = ""
+	}
+
+	return ts, nil
+}
+
+// CheckForServer checks settings of a server.
+func (ts *TlsSettings) CheckForServer() (err error) {
+	if len(ts.CertFile) == 0 {
+		return errors.New(ErrCertFileIsNotSet)
+	}
+
+	if len(ts.KeyFile) == 0 {
+		return errors.New(ErrKeyFileIsNotSet)
+	}
+
+	return nil
+}
+
+// CheckForClient checks settings of a client.
+func (ts *TlsSettings) CheckForClient() (err error) {
+	if (len(ts.CertFile) > 0) && (len(ts.KeyFile) == 0) {
+		return errors.New(ErrKeyFileIsNotSet)
+	}
+
+	if (len(ts.CertFile) == 0) && (len(ts.KeyFile) > 0) {
+		return errors.New(ErrCertFileIsNotSet)
+	}
+
+	return nil
+}
+
+// NewServerConfig creates a TLS configuration of a server.
+func (ts *TlsSettings) NewServerConfig() (cfg *tls.Config, err error) {
+	cfg = &tls.Config{
+		MinVersion: TlsVersionMin,
+	}
+
+	var cert tls.Certificate
+	cert, err = tls.LoadX509KeyPair(ts.CertFile, ts.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Certificates = []tls.Certificate{cert}
+
+	if len(ts.CaFile) > 0 {
+		cfg.ClientCAs, err = loadCertPool(ts.CaFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+// NewClientConfig creates a TLS configuration of a client. Server name is
+// used to verify the certificate of server.
+func (ts *TlsSettings) NewClientConfig(serverName string) (cfg *tls.Config, err error) {
+	cfg = &tls.Config{
+		MinVersion: TlsVersionMin,
+		ServerName: serverName,
+	}
+
+	if len(ts.CertFile) > 0 {
+		var cert tls.Certificate
+		cert, err = tls.LoadX509KeyPair(ts.CertFile, ts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(ts.CaFile) > 0 {
+		cfg.RootCAs, err = loadCertPool(ts.CaFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+func loadCertPool(caFile string) (pool *x509.CertPool, err error) {
+	var pem []byte
+	pem, err = os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool = x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf(ErrCaCertificateIsInvalid, caFile)
+	}
+
+	return pool, nil
+}
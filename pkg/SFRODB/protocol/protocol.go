@@ -4,38 +4,95 @@ import (
 	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Endianness"
 )
 
+// Version of the protocol. Versions with different major numbers are not
+// compatible. Minor versions only add optional features, which are announced
+// in a hello message.
 const (
-	LowLevelProtocol      = "tcp"
-	TcpKeepAliveIsEnabled = true
-	TcpKeepAlivePeriodSec = 15
-	Endianness            = endianness.Endianness_BigEndian
+	VersionMajor = 1
+	VersionMinor = 0
+)
+
+const (
+	LowLevelProtocol       = "tcp"
+	TcpKeepAliveIsEnabled  = true
+	TcpKeepAlivePeriodSec  = 15
+	TlsHandshakeTimeoutSec = 15
+	Endianness             = endianness.Endianness_BigEndian
 
 	RequestSizeLen  = 2
 	ResponseSizeLen = 4
 	MethodNameLen   = 3
 	StatusNameLen   = 3
+	RequestIdLen    = 4
+	UidSizeLen      = 1
 	UidLenMax       = 255
-	ContentLenMax   = 4_294_967_295 - StatusNameLen
+	UidListLenMax   = 1_000
+	ContentLenMax   = 4_294_967_295 - StatusNameLen - RequestIdLen
+
+	// RequestIdNone is a request ID used in messages which are not related to
+	// any request, e.g. when server closes the connection on its own.
+	RequestIdNone = 0
+
+	// VersionTokenLenMax is the maximum length of a version token of a data
+	// record.
+	VersionTokenLenMax = 255
+
+	// RecordListLenMax is the maximum number of UIDs in a single page of a
+	// list of records.
+	RecordListLenMax = 1_000
+
+	// ManyDataVolumeMax is the maximum total volume of data records in a
+	// single response with many data records. Records which do not fit are
+	// not sent, they are marked as too large.
+	ManyDataVolumeMax = 64_000_000 // 64 MB.
 )
 
 // Method strings.
 const (
-	Method_CloseConnection = "CCC"
-	Method_ShowData        = "CSD"
-	Method_SearchRecord    = "CSR"
-	Method_SearchFile      = "CSF"
-	Method_ForgetRecord    = "CFR"
-	Method_ResetCache      = "CRC"
+	Method_CloseConnection    = "CCC"
+	Method_ShowData           = "CSD"
+	Method_SearchRecord       = "CSR"
+	Method_SearchFile         = "CSF"
+	Method_ForgetRecord       = "CFR"
+	Method_ResetCache         = "CRC"
+	Method_ShowDataRange      = "CSP"
+	Method_ShowDataMany       = "CSM"
+	Method_StatRecord         = "CRS"
+	Method_ShowDataIfModified = "CSI"
+	Method_Hello              = "CHI"
+	Method_AuthChallenge      = "CAC"
+	Method_AuthResponse       = "CAR"
+	Method_Ping               = "CPI"
+	Method_ListRecords        = "CLR"
+	Method_Watch              = "CWA"
+	Method_WarmRecords        = "CWR"
+	Method_ShowCacheStats     = "CCS"
+	Method_ShowServerInfo     = "CIN"
 )
 
 // Status strings.
 const (
-	Status_OK                 = "SOK"
-	Status_ClientError        = "SER"
-	Status_ClosingConnection  = "SCC"
-	Status_ShowingData        = "SSD"
-	Status_RecordExists       = "SRE"
-	Status_RecordDoesNotExist = "SRN"
-	Status_FileExists         = "SFE"
-	Status_FileDoesNotExist   = "SFN"
+	Status_OK                    = "SOK"
+	Status_ClientError           = "SER"
+	Status_ClosingConnection     = "SCC"
+	Status_ShowingData           = "SSD"
+	Status_RecordExists          = "SRE"
+	Status_RecordDoesNotExist    = "SRN"
+	Status_FileExists            = "SFE"
+	Status_FileDoesNotExist      = "SFN"
+	Status_ShowingDataRange      = "SSP"
+	Status_ShowingDataMany       = "SSM"
+	Status_ShowingRecordStat     = "SRS"
+	Status_NotModified           = "SNM"
+	Status_ShowingVersionedData  = "SSV"
+	Status_Hello                 = "SHI"
+	Status_ShowingCompressedData = "SSZ"
+	Status_AuthChallenge         = "SAC"
+	Status_Pong                  = "SPO"
+	Status_ShowingRecordList     = "SLR"
+	Status_Event                 = "SEV"
+	Status_RecordsWarmed         = "SRW"
+	Status_ShowingCacheStats     = "SCS"
+	Status_ShowingServerInfo     = "SIN"
+	Status_ItemIsTooLarge        = "STL"
 )
@@ -0,0 +1,133 @@
+package sfrodbtest
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Faults are failures injected into the connections of a test server. They
+// are used for testing how clients and pools of clients recover.
+type Faults struct {
+	// Delay of each piece of data written by the server.
+	latency *atomic.Int64
+
+	// Connections which are accepted while this flag is set are closed at
+	// once, as if the server was not available.
+	isRefusing *atomic.Bool
+
+	// Open connections of both ports.
+	conns     map[*faultyConn]bool
+	connsLock *sync.Mutex
+}
+
+func newFaults() (f *Faults) {
+	return &Faults{
+		latency:    new(atomic.Int64),
+		isRefusing: new(atomic.Bool),
+		conns:      make(map[*faultyConn]bool),
+		connsLock:  new(sync.Mutex),
+	}
+}
+
+// SetLatency delays each response of the server. Zero latency removes the
+// delay.
+func (f *Faults) SetLatency(latency time.Duration) {
+	f.latency.Store(int64(latency))
+}
+
+// SetRefusing makes the server close new connections at once. Connections
+// which are open already are not changed.
+func (f *Faults) SetRefusing(isRefusing bool) {
+	f.isRefusing.Store(isRefusing)
+}
+
+// DropConnections closes all the open connections of the server without
+// telling the clients. It returns the number of dropped connections.
+func (f *Faults) DropConnections() (n int) {
+	f.connsLock.Lock()
+	conns := make([]*faultyConn, 0, len(f.conns))
+	for c := range f.conns {
+		conns = append(conns, c)
+	}
+	f.connsLock.Unlock()
+
+	for _, c := range conns {
+		if c.Close() == nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// ConnectionsCount returns the number of open connections of the server.
+func (f *Faults) ConnectionsCount() (n int) {
+	f.connsLock.Lock()
+	defer f.connsLock.Unlock()
+
+	return len(f.conns)
+}
+
+// faultyListener is a listener which connections are subject to the faults.
+type faultyListener struct {
+	net.Listener
+	faults *Faults
+}
+
+// Accept is the standard method of the 'net.Listener' interface.
+func (fl *faultyListener) Accept() (conn net.Conn, err error) {
+	for {
+		conn, err = fl.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if !fl.faults.isRefusing.Load() {
+			break
+		}
+
+		_ = conn.Close()
+	}
+
+	fc := &faultyConn{Conn: conn, faults: fl.faults, closeOnce: new(sync.Once)}
+
+	fl.faults.connsLock.Lock()
+	defer fl.faults.connsLock.Unlock()
+
+	fl.faults.conns[fc] = true
+	return fc, nil
+}
+
+// faultyConn is a connection which is subject to the faults.
+type faultyConn struct {
+	net.Conn
+	faults    *Faults
+	closeOnce *sync.Once
+}
+
+// Write is the standard method of the 'net.Conn' interface.
+func (fc *faultyConn) Write(b []byte) (n int, err error) {
+	latency := time.Duration(fc.faults.latency.Load())
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	return fc.Conn.Write(b)
+}
+
+// Close is the standard method of the 'net.Conn' interface. Only the first
+// call closes the connection, the next calls return 'net.ErrClosed'.
+func (fc *faultyConn) Close() (err error) {
+	err = net.ErrClosed
+	fc.closeOnce.Do(func() {
+		fc.faults.connsLock.Lock()
+		delete(fc.faults.conns, fc)
+		fc.faults.connsLock.Unlock()
+
+		err = fc.Conn.Close()
+	})
+
+	return err
+}
@@ -0,0 +1,223 @@
+package sfrodbtest
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Client"
+	cs "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ClientSettings"
+	ds "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/DataSettings"
+	poc "github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/PoolOfClients"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/Server"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/socket"
+)
+
+// Data settings of a test server, unless other settings are given.
+const (
+	FileExtensionDefault            = ".txt"
+	CacheVolumeMaxDefault           = 16_000_000 // 16 MB.
+	CachedItemVolumeMaxDefault      = 1_000_000  // 1 MB.
+	CachedItemTTLDefault            = 3600
+	CompressedCacheVolumeMaxDefault = 4_000_000 // 4 MB.
+)
+
+// TestServer is a server running in the process of a test. It listens on
+// ephemeral ports of the loopback interface and serves records of a
+// temporary data folder.
+type TestServer struct {
+	Server *server.Server
+
+	// Folder with data files of the records.
+	Folder string
+
+	// Faults injected into the connections of the server.
+	Faults *Faults
+
+	tb   testing.TB
+	opts server.Options
+
+	// Number of created clients, it is used for IDs of clients.
+	clientsCount *atomic.Int32
+}
+
+// NewServer starts a test server with the records, which are data by UID.
+// The server is stopped by the cleanup of the test.
+func NewServer(tb testing.TB, records map[string][]byte) (ts *TestServer) {
+	tb.Helper()
+
+	return NewServerWithOptions(tb, records, server.Options{})
+}
+
+// NewServerWithOptions starts a test server with the records and options.
+// Data settings of the options are optional, their folder is replaced with a
+// temporary folder. Listeners of the options are replaced with listeners on
+// ephemeral ports. The server is stopped by the cleanup of the test, unless
+// the test has stopped it.
+func NewServerWithOptions(tb testing.TB, records map[string][]byte, opts server.Options) (ts *TestServer) {
+	tb.Helper()
+
+	ts = &TestServer{
+		Folder:       tb.TempDir(),
+		Faults:       newFaults(),
+		tb:           tb,
+		clientsCount: new(atomic.Int32),
+	}
+
+	if opts.Data == nil {
+		opts.Data = &ds.DataSettings{
+			FileExtension:       FileExtensionDefault,
+			CacheVolumeMax:      CacheVolumeMaxDefault,
+			CachedItemVolumeMax: CachedItemVolumeMaxDefault,
+			CachedItemTTL:       CachedItemTTLDefault,
+
+			CompressedCacheVolumeMax: CompressedCacheVolumeMaxDefault,
+		}
+	} else {
+		data := *opts.Data
+		opts.Data = &data
+	}
+	opts.Data.Folder = ts.Folder
+	opts.Data.Archive = ""
+
+	opts.MainListener = ts.listen()
+	opts.AuxListener = ts.listen()
+	ts.opts = opts
+
+	for uid, data := range records {
+		ts.PutRecord(uid, data)
+	}
+
+	var err error
+	ts.Server, err = server.NewWithOptions(&opts)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	cerr := ts.Server.Start()
+	if cerr != nil {
+		tb.Fatal(cerr)
+	}
+
+	tb.Cleanup(func() {
+		if !ts.Server.IsRunning() {
+			return
+		}
+
+		cerr := ts.Server.Stop()
+		if cerr != nil {
+			tb.Error(cerr)
+		}
+	})
+
+	return ts
+}
+
+// listen creates a listener on an ephemeral port which connections are
+// subject to the faults.
+func (ts *TestServer) listen() (listener net.Listener) {
+	l, err := net.Listen(protocol.LowLevelProtocol, server.ListenAddressDefault)
+	if err != nil {
+		ts.tb.Fatal(err)
+	}
+
+	return &faultyListener{Listener: l, faults: ts.Faults}
+}
+
+// PutRecord creates or replaces the data file of a record. Folders of a UID
+// with slashes are created when needed. A record which is cached already is
+// not changed in the cache.
+func (ts *TestServer) PutRecord(uid string, data []byte) {
+	ts.tb.Helper()
+
+	path := ts.recordPath(uid)
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		ts.tb.Fatal(err)
+	}
+
+	err = os.WriteFile(path, data, 0o644)
+	if err != nil {
+		ts.tb.Fatal(err)
+	}
+}
+
+// DeleteRecord deletes the data file of a record. A record which is cached
+// already is not removed from the cache.
+func (ts *TestServer) DeleteRecord(uid string) {
+	ts.tb.Helper()
+
+	err := os.Remove(ts.recordPath(uid))
+	if err != nil {
+		ts.tb.Fatal(err)
+	}
+}
+
+// recordPath returns the path to the data file of a record.
+func (ts *TestServer) recordPath(uid string) (path string) {
+	return filepath.Join(ts.Folder, filepath.FromSlash(uid+ts.opts.Data.FileExtension))
+}
+
+// ClientSettings returns settings of a client connecting to the server.
+func (ts *TestServer) ClientSettings() (stn *cs.ClientSettings) {
+	ts.tb.Helper()
+
+	host, mainPort, _ := socket.SplitAddr(ts.Server.GetMainAddr())
+	_, auxPort, _ := socket.SplitAddr(ts.Server.GetAuxAddr())
+
+	stn, err := cs.New(host, mainPort, auxPort)
+	if err != nil {
+		ts.tb.Fatal(err)
+	}
+
+	stn.AuxSecret = ts.opts.AuxSecret
+	return stn
+}
+
+// NewClient starts a client connected to the server. The client is stopped by
+// the cleanup of the test before the server.
+func (ts *TestServer) NewClient() (cli *client.Client) {
+	ts.tb.Helper()
+
+	cli, err := client.New(ts.ClientSettings(), strconv.Itoa(int(ts.clientsCount.Add(1))))
+	if err != nil {
+		ts.tb.Fatal(err)
+	}
+
+	cerr := cli.Start()
+	if cerr != nil {
+		ts.tb.Fatal(cerr)
+	}
+
+	// A client which connection has been dropped may fail to stop cleanly,
+	// it is not an error of the test.
+	ts.tb.Cleanup(func() {
+		_ = cli.Stop()
+	})
+
+	return cli
+}
+
+// NewPool starts a pool of clients connected to the server. The pool is
+// stopped by the cleanup of the test before the server.
+func (ts *TestServer) NewPool(size int) (pool *poc.PoolOfClients) {
+	ts.tb.Helper()
+
+	pool, err := poc.New(size, ts.ClientSettings())
+	if err != nil {
+		ts.tb.Fatal(err)
+	}
+
+	cerr := pool.Start()
+	if cerr != nil {
+		ts.tb.Fatal(cerr)
+	}
+
+	ts.tb.Cleanup(pool.Stop)
+
+	return pool
+}
@@ -0,0 +1,78 @@
+package sfrodbtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/classes/ErrorCode"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/sfrodbtest"
+)
+
+func Test_Records(t *testing.T) {
+	// Folders of records are created by the test server.
+	ts := sfrodbtest.NewServer(t, map[string][]byte{"a/b/c": []byte("1")})
+	cli := ts.NewClient()
+
+	data, cerr := cli.ShowData("a/b/c")
+	if (cerr != nil) || (string(data) != "1") {
+		t.Fatalf("nested record: %q, %v", data, cerr)
+	}
+
+	ts.PutRecord("d/e", []byte("2"))
+	data, cerr = cli.ShowData("d/e")
+	if (cerr != nil) || (string(data) != "2") {
+		t.Fatalf("added record: %q, %v", data, cerr)
+	}
+
+	ts.DeleteRecord("d/e")
+	cerr = cli.ForgetRecord("d/e")
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	_, cerr = cli.ShowData("d/e")
+	if (cerr == nil) || (cerr.GetCode() != ec.ErrorCode_FileDoesNotExist) {
+		t.Fatalf("deleted record: %v", cerr)
+	}
+}
+
+func Test_Faults(t *testing.T) {
+	ts := sfrodbtest.NewServer(t, map[string][]byte{"a": []byte("1")})
+	cli := ts.NewClient()
+
+	// A client has two connections.
+	n := ts.Faults.ConnectionsCount()
+	if n != 2 {
+		t.Fatalf("unexpected number of connections: %d", n)
+	}
+
+	n = ts.Faults.DropConnections()
+	if n != 2 {
+		t.Fatalf("unexpected number of dropped connections: %d", n)
+	}
+
+	_, cerr := cli.ShowData("a")
+	if cerr == nil {
+		t.Fatal("dropped connection works")
+	}
+
+	// New connections are closed while the server is refusing them.
+	ts.Faults.SetRefusing(true)
+	cerr = cli.Restart(true)
+	if cerr == nil {
+		t.Fatal("refused client is started")
+	}
+
+	ts.Faults.SetRefusing(false)
+	cerr = cli.Restart(true)
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+
+	// Latency does not break responses.
+	ts.Faults.SetLatency(time.Millisecond)
+	data, cerr := cli.ShowData("a")
+	if (cerr != nil) || (string(data) != "1") {
+		t.Fatalf("delayed response: %q, %v", data, cerr)
+	}
+}
@@ -0,0 +1,196 @@
+package socket
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/protocol"
+	"github.com/vault-thirteen/SFRODB/pkg/SFRODB/std/tcp"
+	ae "github.com/vault-thirteen/auxie/errors"
+	"github.com/vault-thirteen/auxie/number"
+)
+
+const (
+	ErrUnixSocketPathIsNotSet = "unix socket path is not set"
+	ErrFileIsNotSocket        = "file exists and is not a socket: %s"
+	ErrAddressTypeIsUnknown   = "unknown address type: %T"
+	ErrSocketIsInUse          = "socket is in use: %s"
+)
+
+const (
+	// UnixPrefix is a prefix of a Unix socket path used instead of a port
+	// number, e.g. 'unix:/run/sfrodb/main.sock'.
+	UnixPrefix = "unix:"
+
+	// UnixNetwork is a name of the network of Unix sockets.
+	UnixNetwork = "unix"
+
+	// UnixSocketFileMode is the access mode of a socket file created by a
+	// listener. Access to the socket is controlled by the file permissions.
+	UnixSocketFileMode fs.FileMode = 0o660
+)
+
+// ParsePort parses either a port number or a path to a Unix socket with the
+// 'unix:' prefix.
+func ParsePort(s string) (port uint16, unixPath string, err error) {
+	s = strings.TrimSpace(s)
+
+	if strings.HasPrefix(s, UnixPrefix) {
+		unixPath = strings.TrimSpace(s[len(UnixPrefix):])
+		if len(unixPath) == 0 {
+			return 0, "", errors.New(ErrUnixSocketPathIsNotSet)
+		}
+
+		return 0, unixPath, nil
+	}
+
+	port, err = number.ParseUint16(s)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return port, "", nil
+}
+
+// Dsn returns the DSN of a TCP address or of a Unix socket. The socket is
+// used when its path is set.
+func Dsn(host string, port uint16, unixPath string) (dsn string) {
+	if len(unixPath) > 0 {
+		return UnixPrefix + unixPath
+	}
+
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+// ResolveAddr returns either a TCP address or an address of a Unix socket. The
+// socket is used when its path is set.
+func ResolveAddr(host string, port uint16, unixPath string) (addr net.Addr, err error) {
+	if len(unixPath) > 0 {
+		return net.ResolveUnixAddr(UnixNetwork, unixPath)
+	}
+
+	return net.ResolveTCPAddr(protocol.LowLevelProtocol, fmt.Sprintf("%s:%d", host, port))
+}
+
+// SplitAddr splits either a TCP address or an address of a Unix socket into
+// the parts used by settings. Parts of other addresses are empty.
+func SplitAddr(addr net.Addr) (host string, port uint16, unixPath string) {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP.String(), uint16(a.Port), ""
+
+	case *net.UnixAddr:
+		return "", 0, a.Name
+
+	default:
+		return "", 0, ""
+	}
+}
+
+// AddrDsn returns the DSN of an address. Addresses which are neither TCP
+// addresses nor Unix sockets are shown as they are.
+func AddrDsn(addr net.Addr) (dsn string) {
+	host, port, unixPath := SplitAddr(addr)
+	if (port == 0) && (len(unixPath) == 0) {
+		return addr.String()
+	}
+
+	return Dsn(host, port, unixPath)
+}
+
+// Listen starts listening on the address. A stale socket file left by a
+// previous run is removed before listening, the new socket file gets the
+// 'UnixSocketFileMode' access mode.
+func Listen(addr net.Addr) (listener net.Listener, err error) {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return net.ListenTCP(protocol.LowLevelProtocol, a)
+
+	case *net.UnixAddr:
+		err = removeStaleSocket(a.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		var ul *net.UnixListener
+		ul, err = net.ListenUnix(UnixNetwork, a)
+		if err != nil {
+			return nil, err
+		}
+
+		err = os.Chmod(a.Name, UnixSocketFileMode)
+		if err != nil {
+			return nil, ae.Combine(err, ul.Close())
+		}
+
+		return ul, nil
+
+	default:
+		return nil, fmt.Errorf(ErrAddressTypeIsUnknown, addr)
+	}
+}
+
+// Dial connects to the address. Keep-alives are enabled for TCP connections.
+func Dial(addr net.Addr) (conn net.Conn, err error) {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		var tcpConn *net.TCPConn
+		tcpConn, err = net.DialTCP(protocol.LowLevelProtocol, nil, a)
+		if err != nil {
+			return nil, err
+		}
+
+		err = EnableKeepAlives(tcpConn)
+		if err != nil {
+			return nil, ae.Combine(err, tcpConn.Close())
+		}
+
+		return tcpConn, nil
+
+	case *net.UnixAddr:
+		return net.DialUnix(UnixNetwork, nil, a)
+
+	default:
+		return nil, fmt.Errorf(ErrAddressTypeIsUnknown, addr)
+	}
+}
+
+// EnableKeepAlives enables keep-alives for TCP connections. Other connections
+// are not changed.
+func EnableKeepAlives(conn net.Conn) (err error) {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	return tcp.EnableKeepAlives(tcpConn, protocol.TcpKeepAliveIsEnabled, protocol.TcpKeepAlivePeriodSec)
+}
+
+// removeStaleSocket removes a socket file which is not used by anybody. Files
+// which are not sockets and sockets which are in use are not removed.
+func removeStaleSocket(path string) (err error) {
+	var fi os.FileInfo
+	fi, err = os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if fi.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf(ErrFileIsNotSocket, path)
+	}
+
+	var conn net.Conn
+	conn, err = net.Dial(UnixNetwork, path)
+	if err == nil {
+		return ae.Combine(fmt.Errorf(ErrSocketIsInUse, path), conn.Close())
+	}
+
+	return os.Remove(path)
+}